@@ -0,0 +1,94 @@
+package rfm95
+
+import (
+	"fmt"
+	"log"
+)
+
+// NodeAddress returns the radio's node address.
+func (r *Radio) NodeAddress() byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hw.ReadRegister(RegNodeAdrs)
+}
+
+// SetNodeAddress sets the radio's node address.
+func (r *Radio) SetNodeAddress(addr byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hw.WriteRegister(RegNodeAdrs, addr)
+}
+
+// BroadcastAddress returns the radio's broadcast address.
+func (r *Radio) BroadcastAddress() byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hw.ReadRegister(RegBroadcastAdrs)
+}
+
+// SetBroadcastAddress sets the radio's broadcast address.
+func (r *Radio) SetBroadcastAddress(addr byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hw.WriteRegister(RegBroadcastAdrs, addr)
+}
+
+// AddressFiltering returns the radio's address filtering mode
+// (AddressFilteringNone, AddressFilteringNode, or AddressFilteringNodeOrBroadcast).
+func (r *Radio) AddressFiltering() byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hw.ReadRegister(RegPacketConfig1) & AddressFilteringMask
+}
+
+// SetAddressFiltering sets the radio's address filtering mode
+// (AddressFilteringNone, AddressFilteringNode, or AddressFilteringNodeOrBroadcast).
+// When filtering is enabled, the first byte of each packet is its destination
+// address, and the packet engine ignores packets addressed to other nodes.
+func (r *Radio) SetAddressFiltering(mode byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch mode {
+	case AddressFilteringNone, AddressFilteringNode, AddressFilteringNodeOrBroadcast:
+	default:
+		r.setError(fmt.Errorf("invalid address filtering mode %02X", mode))
+		return
+	}
+	cfg := r.hw.ReadRegister(RegPacketConfig1)
+	r.hw.WriteRegister(RegPacketConfig1, cfg&^AddressFilteringMask|mode)
+}
+
+// SendTo transmits the given packet to the node with the given address.
+func (r *Radio) SendTo(dst byte, data []byte) {
+	if len(data)+1 > maxPacketSize {
+		log.Panicf("attempting to send %d-byte packet to %02X", len(data), dst)
+	}
+	packet := make([]byte, 1+len(data))
+	packet[0] = dst
+	copy(packet[1:], data)
+	r.Send(packet)
+}
+
+// acceptAddress reports whether a packet with the given destination address
+// should be received. Packets that the packet engine would have discarded
+// can still arrive through the unlimited-length receive path.
+func (r *Radio) acceptAddress(addr byte) bool {
+	mode := r.hw.ReadRegister(RegPacketConfig1) & AddressFilteringMask
+	if mode == AddressFilteringNone {
+		return true
+	}
+	return addressMatch(mode, r.hw.ReadRegister(RegNodeAdrs), r.hw.ReadRegister(RegBroadcastAdrs), addr)
+}
+
+func addressMatch(mode byte, node byte, broadcast byte, addr byte) bool {
+	switch mode {
+	case AddressFilteringNone:
+		return true
+	case AddressFilteringNode:
+		return addr == node
+	case AddressFilteringNodeOrBroadcast:
+		return addr == node || addr == broadcast
+	default:
+		return false
+	}
+}
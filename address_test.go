@@ -0,0 +1,28 @@
+package rfm95
+
+import (
+	"testing"
+)
+
+func TestAddressMatch(t *testing.T) {
+	cases := []struct {
+		mode byte
+		addr byte
+		ok   bool
+	}{
+		{AddressFilteringNone, 0x12, true},
+		{AddressFilteringNone, 0x99, true},
+		{AddressFilteringNode, 0x12, true},
+		{AddressFilteringNode, 0xFF, false},
+		{AddressFilteringNode, 0x99, false},
+		{AddressFilteringNodeOrBroadcast, 0x12, true},
+		{AddressFilteringNodeOrBroadcast, 0xFF, true},
+		{AddressFilteringNodeOrBroadcast, 0x99, false},
+	}
+	for _, c := range cases {
+		ok := addressMatch(c.mode, 0x12, 0xFF, c.addr)
+		if ok != c.ok {
+			t.Errorf("addressMatch(%02X, 12, FF, %02X) == %v, want %v", c.mode, c.addr, ok, c.ok)
+		}
+	}
+}
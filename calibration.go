@@ -0,0 +1,177 @@
+package rfm95
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Crystal frequency tolerance limit accepted by SetPPM, in parts per million.
+const maxPPM = 100
+
+// xo returns the oscillator frequency, corrected for crystal error.
+func (r *Radio) xo() uint32 {
+	return correctedXO(r.ppm)
+}
+
+func correctedXO(ppm float64) uint32 {
+	return uint32(math.Round(FXOSC * (1 + ppm/1e6)))
+}
+
+// PPM returns the crystal frequency correction, in parts per million.
+func (r *Radio) PPM() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ppm
+}
+
+// SetPPM sets the crystal frequency correction, in parts per million.
+// A positive value means the crystal runs faster than its nominal 32 MHz.
+// The current frequency, bit rate, and channel bandwidth are reprogrammed
+// using the new value.
+func (r *Radio) SetPPM(ppm float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if math.IsNaN(ppm) || math.Abs(ppm) > maxPPM {
+		r.setError(fmt.Errorf("crystal correction %g ppm is out of range", ppm))
+		return
+	}
+	freq := r.frequency()
+	br := r.readBitrate()
+	bw := r.channelBW()
+	if r.error() != nil {
+		return
+	}
+	r.ppm = ppm
+	r.hw.WriteBurst(RegFrfMsb, frequencyToRegisters(freq, r.xo()))
+	r.setBitrate(br)
+	r.setChannelBW(bw)
+}
+
+// Calibration records the crystal correction for a radio module.
+type Calibration struct {
+	PPM       float64   `json:"ppm"`
+	Reference uint32    `json:"reference,omitempty"` // frequency of the reference transmitter, in Hz
+	Samples   int       `json:"samples,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// DefaultCalibrationFile is the file from which Open loads the
+// crystal correction, if it exists. It can be overridden by the
+// RFM95_CALIBRATION environment variable.
+func DefaultCalibrationFile() string {
+	file := os.Getenv("RFM95_CALIBRATION")
+	if file != "" {
+		return file
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "rfm95", "calibration.json")
+}
+
+// ReadCalibration reads a calibration from the given file.
+func ReadCalibration(file string) (Calibration, error) {
+	var c Calibration
+	f, err := os.Open(file)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	err = json.NewDecoder(f).Decode(&c)
+	if err != nil {
+		return c, fmt.Errorf("%s: %v", file, err)
+	}
+	if math.Abs(c.PPM) > maxPPM {
+		return c, fmt.Errorf("%s: crystal correction %g ppm is out of range", file, c.PPM)
+	}
+	return c, nil
+}
+
+// Save writes the calibration to the given file,
+// creating its directory if necessary.
+func (c Calibration) Save(file string) error {
+	err := os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// loadCalibration applies the calibration in DefaultCalibrationFile, if any.
+func (r *Radio) loadCalibration() {
+	file := DefaultCalibrationFile()
+	if file == "" {
+		return
+	}
+	c, err := ReadCalibration(file)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		r.logger.Warn("ignoring calibration", "file", file, "err", err)
+		return
+	}
+	r.ppm = c.PPM
+}
+
+// ppmFromFEI returns the crystal correction implied by the mean
+// frequency error of signals from a reference transmitter at freq,
+// received using the given correction.
+//
+// A crystal that runs fast by p ppm (relative to the current correction)
+// tunes the receiver p ppm above freq, so the measured error is -p ppm.
+func ppmFromFEI(current float64, fei float64, freq uint32) float64 {
+	return current - fei/float64(freq)*1e6
+}
+
+// Calibrate measures the frequency error of packets received from
+// a reference transmitter at the radio's current frequency
+// and adjusts the crystal correction accordingly.
+// The reference must use FSK modulation, since the frequency error
+// is not measured with OOK. Calibrate waits up to timeout for each
+// of n packets, and returns the resulting calibration.
+// If a packet does not arrive in time, Calibrate stops, sets the radio's
+// error state, and returns the zero Calibration.
+func (r *Radio) Calibrate(n int, timeout time.Duration) Calibration {
+	if n < 1 {
+		log.Panicf("Calibrate: invalid number of packets (%d)", n)
+	}
+	logger := r.Logger()
+	freq := r.Frequency()
+	sum, count := 0, 0
+	deadline := time.Now().Add(timeout)
+	for count < n && r.Error() == nil {
+		// Packets that are discarded after reception do not extend the deadline.
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			r.mu.Lock()
+			r.setError(fmt.Errorf("calibration: no packet received within %v (%d of %d received)", timeout, count, n))
+			r.mu.Unlock()
+			break
+		}
+		p, _ := r.Receive(remaining)
+		if p == nil {
+			continue
+		}
+		sum += r.FEI()
+		count++
+		deadline = time.Now().Add(timeout)
+		logger.Debug("calibration packet", "count", count, "fei", r.FEI())
+	}
+	if r.Error() != nil {
+		return Calibration{}
+	}
+	ppm := ppmFromFEI(r.PPM(), float64(sum)/float64(count), freq)
+	r.SetPPM(ppm)
+	return Calibration{PPM: r.PPM(), Reference: freq, Samples: count, Time: time.Now()}
+}
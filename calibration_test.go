@@ -0,0 +1,128 @@
+package rfm95
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCorrectedXO(t *testing.T) {
+	cases := []struct {
+		ppm float64
+		xo  uint32
+	}{
+		{0, 32000000},
+		{10, 32000320},
+		{-2.5, 31999920},
+		{100, 32003200},
+	}
+	for _, c := range cases {
+		xo := correctedXO(c.ppm)
+		if xo != c.xo {
+			t.Errorf("correctedXO(%g) == %d, want %d", c.ppm, xo, c.xo)
+		}
+	}
+}
+
+func TestCorrectedFrequency(t *testing.T) {
+	// A crystal running 10 ppm fast requires a smaller Frf value,
+	// which the nominal conversion interprets as a lower frequency.
+	const freq = 916600000
+	b := frequencyToRegisters(freq, correctedXO(10))
+	nominal := registersToFrequency(b, FXOSC)
+	want := uint32(math.Round(freq / (1 + 10e-6)))
+	if diff := int(nominal) - int(want); diff < -62 || diff > 62 {
+		t.Errorf("frequencyToRegisters(%d, +10 ppm) has nominal frequency %d, want %d", freq, nominal, want)
+	}
+	f := registersToFrequency(b, correctedXO(10))
+	if diff := int(f) - freq; diff < -62 || diff > 62 {
+		t.Errorf("registersToFrequency(% X, +10 ppm) == %d, want %d", b, f, freq)
+	}
+}
+
+func TestPPMFromFEI(t *testing.T) {
+	cases := []struct {
+		current float64
+		fei     float64
+		freq    uint32
+		ppm     float64
+	}{
+		{0, 0, 916600000, 0},
+		{0, -9166, 916600000, 10},
+		{0, 4583, 916600000, -5},
+		{10, 4583, 916600000, 5},
+	}
+	for _, c := range cases {
+		ppm := ppmFromFEI(c.current, c.fei, c.freq)
+		if math.Abs(ppm-c.ppm) > 1e-9 {
+			t.Errorf("ppmFromFEI(%g, %g, %d) == %g, want %g", c.current, c.fei, c.freq, ppm, c.ppm)
+		}
+	}
+}
+
+func TestCalibrationFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sub", "calibration.json")
+	c := Calibration{PPM: -7.25, Reference: 916600000, Samples: 20, Time: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)}
+	err := c.Save(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ReadCalibration(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.PPM != c.PPM || d.Reference != c.Reference || d.Samples != c.Samples || !d.Time.Equal(c.Time) {
+		t.Errorf("ReadCalibration == %+v, want %+v", d, c)
+	}
+	c.PPM = 500
+	err = c.Save(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadCalibration(file)
+	if err == nil {
+		t.Errorf("ReadCalibration accepted out-of-range correction")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	r, hw := newFakeRadio()
+	r.SetFrequency(915000000)
+	r.SetBitrate(16384)
+	hw.rx = []byte("ab\x00cd\x00")
+	c := r.Calibrate(2, 50*time.Millisecond)
+	if r.Error() != nil {
+		t.Fatal(r.Error())
+	}
+	if c.Samples != 2 || c.PPM != 0 {
+		t.Errorf("Calibrate == %+v, want 2 samples and 0 ppm", c)
+	}
+	// Calibrate must give up when the reference transmitter is silent.
+	hw.rx = []byte("ab\x00")
+	c = r.Calibrate(2, 50*time.Millisecond)
+	if r.Error() == nil {
+		t.Fatalf("Calibrate without packets returned %+v and no error", c)
+	}
+	if c != (Calibration{}) {
+		t.Errorf("Calibrate without packets returned %+v", c)
+	}
+}
+
+func TestSetPPMChannelBW(t *testing.T) {
+	r, _ := newFakeRadio()
+	r.SetFrequency(915000000)
+	r.SetBitrate(16384)
+	r.SetChannelBW(100000)
+	before := r.ChannelBW()
+	r.SetPPM(50)
+	if r.Error() != nil {
+		t.Fatal(r.Error())
+	}
+	// The nearest setting is the same register value,
+	// interpreted with the corrected oscillator frequency.
+	after := r.ChannelBW()
+	if before != 100000 || after != 100005 {
+		t.Errorf("ChannelBW == %d before and %d after SetPPM(50), want 100000 and 100005", before, after)
+	}
+}
@@ -0,0 +1,145 @@
+package rfm95
+
+// chip describes the register-level differences between
+// the SX1276 family (RFM95/96/97/98) and the SX1272 family (RFM92).
+type chip struct {
+	name    string
+	version uint16
+
+	// Default variant when the chip is detected but not configured.
+	variant Variant
+
+	// Addresses of registers that are not at the same location in both maps.
+	regPllHop      byte
+	regTcxo        byte
+	regPaDac       byte
+	regBitRateFrac byte
+
+	// Location of the 2-bit ModulationShaping field.
+	shapingReg   byte
+	shapingShift uint
+
+	// Whether RegOpMode has a LowFrequencyModeOn bit.
+	hasLowFrequencyMode bool
+
+	// LoRa signal bandwidths in Hertz, indexed by their RegLoRaModemConfig1 encoding.
+	loraBandwidths  []uint32
+	loraBwShift     uint
+	loraBwFieldMask byte
+
+	// Register values that differ from resetConfiguration and defaultConfiguration.
+	configOverrides map[byte]byte
+}
+
+const (
+	sx1276Version = 0x0102
+	sx1272Version = 0x0202
+)
+
+var sx1276 = &chip{
+	name:    "SX1276",
+	version: sx1276Version,
+	variant: SX1276,
+
+	regPllHop:      RegPllHop,
+	regTcxo:        RegTcxo,
+	regPaDac:       RegPaDac,
+	regBitRateFrac: RegBitRateFrac,
+
+	shapingReg:   RegPaRamp,
+	shapingShift: 5,
+
+	hasLowFrequencyMode: true,
+
+	loraBandwidths: []uint32{
+		7800, 10400, 15600, 20800, 31250, 41700, 62500, 125000, 250000, 500000,
+	},
+	loraBwShift:     4,
+	loraBwFieldMask: 0xF << 4,
+}
+
+// See the SX1272/73 data sheet, section 6.
+var sx1272 = &chip{
+	name:    "SX1272",
+	version: sx1272Version,
+	variant: SX1272,
+
+	regPllHop:      SX1272RegPllHop,
+	regTcxo:        SX1272RegTcxo,
+	regPaDac:       SX1272RegPaDac,
+	regBitRateFrac: SX1272RegBitRateFrac,
+
+	shapingReg:   RegOpMode,
+	shapingShift: 3,
+
+	hasLowFrequencyMode: false,
+
+	loraBandwidths:  []uint32{125000, 250000, 500000},
+	loraBwShift:     6,
+	loraBwFieldMask: 3 << 6,
+
+	configOverrides: map[byte]byte{
+		RegPaConfig: 0x0F,
+		RegPaRamp:   0x19,
+		RegVersion:  0x22,
+	},
+}
+
+var chips = []*chip{sx1276, sx1272}
+
+// chipForVersion returns the chip with the given hardware version, or nil.
+func chipForVersion(v uint16) *chip {
+	for _, c := range chips {
+		if c.version == v {
+			return c
+		}
+	}
+	return nil
+}
+
+// configuration returns a copy of base with the chip's overrides applied.
+func (c *chip) configuration(base []byte) []byte {
+	config := make([]byte, len(base))
+	copy(config, base)
+	for addr, v := range c.configOverrides {
+		config[addr] = v
+	}
+	return config
+}
+
+// setModulationShaping stores the given shaping value (one of the
+// ModulationShaping constants) in the appropriate register of config.
+func (c *chip) setModulationShaping(config []byte, shaping byte) {
+	v := (shaping >> ModulationShapingShift) & 3
+	config[c.shapingReg] = config[c.shapingReg]&^(3<<c.shapingShift) | v<<c.shapingShift
+}
+
+// modulationShaping returns the shaping value in config,
+// as one of the ModulationShaping constants.
+func (c *chip) modulationShaping(config []byte) byte {
+	v := (config[c.shapingReg] >> c.shapingShift) & 3
+	return v << ModulationShapingShift
+}
+
+// loraBandwidthToRegister returns the encoding of the narrowest
+// LoRa bandwidth that is at least bw, or the widest available.
+func (c *chip) loraBandwidthToRegister(bw uint32) byte {
+	i := 0
+	for i < len(c.loraBandwidths)-1 && c.loraBandwidths[i] < bw {
+		i++
+	}
+	return byte(i) << c.loraBwShift
+}
+
+func (c *chip) registerToLoRaBandwidth(v byte) uint32 {
+	i := int((v & c.loraBwFieldMask) >> c.loraBwShift)
+	if i >= len(c.loraBandwidths) {
+		return 0
+	}
+	return c.loraBandwidths[i]
+}
+
+// chip returns the register map description for the radio.
+func (r *Radio) chip() *chip {
+	return r.variant.info().chip
+}
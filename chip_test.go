@@ -0,0 +1,110 @@
+package rfm95
+
+import (
+	"testing"
+)
+
+func TestChipForVersion(t *testing.T) {
+	if c := chipForVersion(0x0102); c != sx1276 {
+		t.Errorf("chipForVersion(0102) == %v, want SX1276", c)
+	}
+	if c := chipForVersion(0x0202); c != sx1272 {
+		t.Errorf("chipForVersion(0202) == %v, want SX1272", c)
+	}
+	if c := chipForVersion(0x0101); c != nil {
+		t.Errorf("chipForVersion(0101) == %v, want nil", c)
+	}
+}
+
+func TestModulationShaping(t *testing.T) {
+	cases := []struct {
+		c      *chip
+		reg    byte
+		before byte
+		after  byte
+	}{
+		{sx1276, RegPaRamp, 0x09, 0x29},
+		{sx1272, RegOpMode, 0x21, 0x29},
+	}
+	for _, c := range cases {
+		config := c.c.configuration(DefaultConfiguration())
+		config[c.reg] = c.before
+		c.c.setModulationShaping(config, ModulationShapingNarrow)
+		if config[c.reg] != c.after {
+			t.Errorf("%s: setModulationShaping set %02X to %02X, want %02X", c.c.name, c.reg, config[c.reg], c.after)
+		}
+		if s := c.c.modulationShaping(config); s != ModulationShapingNarrow {
+			t.Errorf("%s: modulationShaping == %02X, want %02X", c.c.name, s, ModulationShapingNarrow)
+		}
+	}
+}
+
+func TestConfigurationOverrides(t *testing.T) {
+	base := DefaultConfiguration()
+	config := sx1272.configuration(base)
+	if config[RegVersion] != 0x22 || config[RegPaConfig] != 0x0F {
+		t.Errorf("SX1272 configuration has RegVersion %02X, RegPaConfig %02X", config[RegVersion], config[RegPaConfig])
+	}
+	if base[RegVersion] != 0x12 || DefaultConfiguration()[RegVersion] != 0x12 {
+		t.Errorf("configuration modified its base")
+	}
+}
+
+func TestLoRaBandwidth(t *testing.T) {
+	cases := []struct {
+		c        *chip
+		bw       uint32
+		r        byte
+		bwApprox uint32 // 0 => equal to bw
+	}{
+		{sx1276, 7800, 0x00, 0},
+		{sx1276, 125000, 0x70, 0},
+		{sx1276, 500000, 0x90, 0},
+		{sx1272, 125000, 0x00, 0},
+		{sx1272, 250000, 0x40, 0},
+		{sx1272, 500000, 0x80, 0},
+		// some that can't be represented exactly:
+		{sx1276, 100000, 0x70, 125000},
+		{sx1276, 1000000, 0x90, 500000},
+		{sx1272, 62500, 0x00, 125000},
+		{sx1272, 1000000, 0x80, 500000},
+	}
+	for _, c := range cases {
+		r := c.c.loraBandwidthToRegister(c.bw)
+		if r != c.r {
+			t.Errorf("%s: loraBandwidthToRegister(%d) == %02X, want %02X", c.c.name, c.bw, r, c.r)
+		}
+		want := c.bw
+		if c.bwApprox != 0 {
+			want = c.bwApprox
+		}
+		bw := c.c.registerToLoRaBandwidth(c.r | 0x02)
+		if bw != want {
+			t.Errorf("%s: registerToLoRaBandwidth(%02X) == %d, want %d", c.c.name, c.r, bw, want)
+		}
+	}
+}
+
+func TestOutputPower(t *testing.T) {
+	cases := []struct {
+		dBm      int
+		paConfig byte
+		paDac    byte
+	}{
+		{2, 0x80, PaDacDefault},
+		{3, 0x81, PaDacDefault},
+		{17, 0x8F, PaDacDefault},
+		{18, 0x8D, PaDacPlus20dBm},
+		{20, 0x8F, PaDacPlus20dBm},
+	}
+	for _, c := range cases {
+		paConfig, paDac := powerToRegisters(c.dBm)
+		if paConfig != c.paConfig || paDac != c.paDac {
+			t.Errorf("powerToRegisters(%d) == %02X, %02X, want %02X, %02X", c.dBm, paConfig, paDac, c.paConfig, c.paDac)
+		}
+		p := registersToPower(c.paConfig, 0x80|c.paDac)
+		if p != c.dBm {
+			t.Errorf("registersToPower(%02X, %02X) == %d, want %d", c.paConfig, 0x80|c.paDac, p, c.dBm)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+var (
+	freqFlag      = flag.Float64("f", 916.6, "`frequency` of the reference transmitter (in MHz or Hz)")
+	deviationFlag = flag.Uint("deviation", 5000, "FSK frequency deviation of the reference, in `Hz`")
+	countFlag     = flag.Int("n", 20, "average the frequency error of `count` packets")
+	timeoutFlag   = flag.Duration("t", 10*time.Second, "wait `duration` for each packet")
+	outputFlag    = flag.String("o", rfm95.DefaultCalibrationFile(), "save calibration to `file`")
+	resetFlag     = flag.Bool("reset", false, "start from zero correction instead of the saved value")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Parse()
+	if flag.NArg() != 0 || *outputFlag == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	freq, err := rfm95.Hertz(*freqFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Init(freq)
+	if *resetFlag {
+		r.SetPPM(0)
+	}
+	// The frequency error is only measured with FSK modulation.
+	s := r.Settings()
+	s.Modulation = rfm95.ModulationTypeFSK
+	s.Deviation = uint32(*deviationFlag)
+	r.ApplySettings(s)
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	log.Printf("starting from %+.2f ppm; waiting for %d packets at %d Hz", r.PPM(), *countFlag, r.Frequency())
+	c := r.Calibrate(*countFlag, *timeoutFlag)
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	log.Printf("crystal correction is %+.2f ppm", c.PPM)
+	err = c.Save(*outputFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("saved calibration to %s", *outputFlag)
+}
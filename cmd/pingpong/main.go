@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+// Packets are sent as text so they never contain the zero byte
+// that terminates a packet:
+//
+//	ping:  "P <seq>"
+//	reply: "E <seq> <rssi> <fei>"
+//
+// where rssi and fei are measured by the echoing radio.
+
+var (
+	echoFlag     = flag.Bool("echo", false, "echo packets received from the other radio")
+	freqFlag     = flag.Float64("f", 916.6, "use `frequency` (in MHz or Hz)")
+	powerFlag    = flag.Int("power", 0, "set output power to `dBm` (0 = default)")
+	countFlag    = flag.Int("n", 100, "send `count` packets (0 = until interrupted)")
+	intervalFlag = flag.Duration("i", 250*time.Millisecond, "wait `duration` between packets")
+	timeoutFlag  = flag.Duration("t", 500*time.Millisecond, "wait `duration` for each reply")
+	reportFlag   = flag.Int("report", 10, "report statistics every `n` packets")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	freq, err := rfm95.Hertz(*freqFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Init(freq)
+	if *powerFlag != 0 {
+		r.SetOutputPower(*powerFlag)
+	}
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	if *echoFlag {
+		echo(r)
+	} else {
+		ping(r)
+	}
+}
+
+func echo(r *rfm95.Radio) {
+	for {
+		data, rssi := r.Receive(time.Hour)
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		var seq int
+		_, err := fmt.Sscanf(string(data), "P %d", &seq)
+		if err != nil {
+			log.Printf("ignoring packet % X", data)
+			continue
+		}
+		fei := r.FEI()
+		r.Send([]byte(fmt.Sprintf("E %d %d %d", seq, rssi, fei)))
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		log.Printf("echoed %d (RSSI = %d, FEI = %d)", seq, rssi, fei)
+	}
+}
+
+// stats accumulates measurements for a reporting interval.
+type stats struct {
+	sent, received        int
+	rtt                   time.Duration
+	localRSSI, localFEI   int
+	remoteRSSI, remoteFEI int
+}
+
+func (s *stats) add(rtt time.Duration, localRSSI, localFEI, remoteRSSI, remoteFEI int) {
+	s.received++
+	s.rtt += rtt
+	s.localRSSI += localRSSI
+	s.localFEI += localFEI
+	s.remoteRSSI += remoteRSSI
+	s.remoteFEI += remoteFEI
+}
+
+func (s *stats) report(label string) {
+	if s.sent == 0 {
+		return
+	}
+	per := 100 * float64(s.sent-s.received) / float64(s.sent)
+	if s.received == 0 {
+		log.Printf("%s: sent %d, received 0, PER %.1f%%", label, s.sent, per)
+		return
+	}
+	n := s.received
+	log.Printf("%s: sent %d, received %d, PER %.1f%%, RTT %v, RSSI %d/%d dBm, FEI %d/%d Hz (local/remote)",
+		label, s.sent, n, per, (s.rtt / time.Duration(n)).Round(time.Microsecond),
+		s.localRSSI/n, s.remoteRSSI/n, s.localFEI/n, s.remoteFEI/n)
+}
+
+func ping(r *rfm95.Radio) {
+	var interval, total stats
+	for seq := 1; *countFlag == 0 || seq <= *countFlag; seq++ {
+		if seq != 1 {
+			time.Sleep(*intervalFlag)
+		}
+		interval.sent++
+		total.sent++
+		start := time.Now()
+		data, rssi := r.SendAndReceive([]byte(fmt.Sprintf("P %d", seq)), *timeoutFlag)
+		rtt := time.Since(start)
+		if data == nil {
+			// Clear the timeout error.
+			r.SetError(nil)
+			log.Printf("%d: no reply", seq)
+		} else {
+			var n, remoteRSSI, remoteFEI int
+			_, err := fmt.Sscanf(string(data), "E %d %d %d", &n, &remoteRSSI, &remoteFEI)
+			switch {
+			case err != nil:
+				log.Printf("%d: invalid reply % X", seq, data)
+			case n != seq:
+				log.Printf("%d: reply to %d", seq, n)
+			default:
+				fei := r.FEI()
+				interval.add(rtt, rssi, fei, remoteRSSI, remoteFEI)
+				total.add(rtt, rssi, fei, remoteRSSI, remoteFEI)
+			}
+		}
+		if *reportFlag != 0 && seq%*reportFlag == 0 {
+			interval.report(fmt.Sprintf("packets %d-%d", seq-interval.sent+1, seq))
+			interval = stats{}
+		}
+	}
+	interval.report("last packets")
+	total.report("total")
+}
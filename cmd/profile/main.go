@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/ecc1/rfm95"
+)
+
+func main() {
+	if len(os.Args) != 3 || (os.Args[1] != "save" && os.Args[1] != "load") {
+		log.Fatalf("Usage: %s save|load file.json|file.yaml", os.Args[0])
+	}
+	file := os.Args[2]
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	switch os.Args[1] {
+	case "save":
+		p := r.Profile(file)
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		err := p.Save(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("saved radio configuration to %s", file)
+	case "load":
+		p, err := rfm95.LoadProfile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.ApplyProfile(p)
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		log.Printf("applied configuration from %s", file)
+	}
+}
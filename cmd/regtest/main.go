@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/ecc1/rfm95"
 )
@@ -37,10 +38,10 @@ func checkRegs(r *rfm95.Radio) {
 	regs0 := r.ReadConfiguration(false)
 	regs1 := r.ReadConfiguration(true)
 	if len(regs0) != len(resetValue) {
-		log.Fatal("%d individual registers, expected %d", len(regs0), len(resetValue))
+		log.Fatalf("%d individual registers, expected %d", len(regs0), len(resetValue))
 	}
 	if len(regs1) != len(resetValue) {
-		log.Fatal("%d burst-mode registers, expected %d", len(regs1), len(resetValue))
+		log.Fatalf("%d burst-mode registers, expected %d", len(regs1), len(resetValue))
 	}
 	mismatches := 0
 	for i, v := range regs0 {
@@ -55,14 +56,9 @@ func checkRegs(r *rfm95.Radio) {
 		fmt.Printf("WARNING: burst read did not match %d of %d single reads\n", mismatches, len(regs0))
 	}
 	fmt.Println("Configuration registers:")
-	for i, v := range regs1 {
-		fmt.Printf("%02X  %02X  %08b", i, v, v)
-		r := resetValue[i]
-		if v == r {
-			fmt.Printf("\n")
-		} else {
-			fmt.Printf("  **** SHOULD BE %02X  %08b\n", r, r)
-		}
+	err := rfm95.FormatConfiguration(os.Stdout, regs1)
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
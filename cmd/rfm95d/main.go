@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ecc1/rfm95"
+	"github.com/ecc1/rfm95/remote"
+)
+
+var (
+	networkFlag = flag.String("network", "unix", "listen on `network` (unix or tcp)")
+	addressFlag = flag.String("address", "/tmp/rfm95.sock", "listen on `address` (socket path or loopback host:port)")
+	freqFlag    = flag.Float64("f", 916.6, "initialize radio to `frequency` (in MHz or Hz)")
+	recordFlag  = flag.String("record", "", "record hardware transactions to `file` for replay")
+	metricsFlag = flag.String("metrics", "", "serve /metrics and /debug/vars on HTTP `address` (host:port)")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Parse()
+	l := listen()
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	var rec *os.File
+	if *recordFlag != "" {
+		rec = record(r)
+	}
+	freq, err := rfm95.Hertz(*freqFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Init(freq)
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	if *metricsFlag != "" {
+		serveMetrics(r)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		_ = l.Close()
+	}()
+	log.Printf("serving %s on %s %s", r.Name(), *networkFlag, *addressFlag)
+	err = remote.NewServer(r).Serve(l)
+	r.Close()
+	if rec != nil {
+		if err := r.StopRecording(); err != nil {
+			log.Print(err)
+		}
+		_ = rec.Close()
+	}
+	log.Print(err)
+}
+
+func record(r *rfm95.Radio) *os.File {
+	f, err := os.Create(*recordFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = r.Record(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return f
+}
+
+func listen() net.Listener {
+	switch *networkFlag {
+	case "unix":
+		// Remove a stale socket left by a previous instance.
+		if fi, err := os.Lstat(*addressFlag); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(*addressFlag)
+		}
+	case "tcp", "tcp4", "tcp6":
+		// There is no authentication, so only allow local clients.
+		a, err := net.ResolveTCPAddr(*networkFlag, *addressFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if a.IP == nil || !a.IP.IsLoopback() {
+			log.Fatalf("%s is not a loopback address", *addressFlag)
+		}
+	default:
+		log.Fatalf("unsupported network %q", *networkFlag)
+	}
+	l, err := net.Listen(*networkFlag, *addressFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return l
+}
+
+// serveMetrics serves the radio's counters in Prometheus format on /metrics
+// and as the expvar variable "rfm95" on /debug/vars.
+func serveMetrics(r *rfm95.Radio) {
+	r.PublishStats("rfm95")
+	http.Handle("/metrics", r.MetricsHandler())
+	l, err := net.Listen("tcp", *metricsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		log.Print(http.Serve(l, nil))
+	}()
+}
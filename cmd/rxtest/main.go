@@ -1,24 +1,39 @@
 package main
 
 import (
+	"flag"
 	"log"
-	"os"
-	"strconv"
 	"time"
 
 	"github.com/ecc1/rfm95"
 )
 
+var (
+	planFlag    = flag.String("plan", "", "validate frequency against channel `plan` (US915, EU868, AU915, AS923)")
+	channelFlag = flag.Int("channel", -1, "tune to channel `n` of the channel plan")
+)
+
 func main() {
 	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
-	if len(os.Args) != 2 {
-		log.Fatalf("Usage: %s frequency", os.Args[0])
+	flag.Usage = func() {
+		log.Printf("Usage: %s [-plan name] [-channel n | frequency]", flag.CommandLine.Name())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	var plan *rfm95.ChannelPlan
+	if *planFlag != "" {
+		var err error
+		plan, err = rfm95.LookupChannelPlan(*planFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
-	frequency := getFrequency(os.Args[1])
+	frequency := getFrequency(plan)
 	r := rfm95.Open()
 	if r.Error() != nil {
 		log.Fatal(r.Error())
 	}
+	r.SetChannelPlan(plan)
 	log.Printf("setting frequency to %d", frequency)
 	r.Init(frequency)
 	for r.Error() == nil {
@@ -28,16 +43,39 @@ func main() {
 	log.Fatal(r.Error())
 }
 
-func getFrequency(s string) uint32 {
-	f, err := strconv.ParseFloat(s, 64)
+func getFrequency(plan *rfm95.ChannelPlan) uint32 {
+	if *channelFlag >= 0 {
+		if plan == nil {
+			log.Fatal("-channel requires -plan")
+		}
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatal("cannot specify both channel and frequency")
+		}
+		f, err := plan.Frequency(*channelFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return f
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		log.Fatal("missing frequency")
+	}
+	s := flag.Arg(0)
+	freq, err := rfm95.ParseFrequency(s)
 	if err != nil {
 		log.Fatal(err)
 	}
-	if 860.0 <= f && f <= 920.0 {
-		return uint32(f * 1000000.0)
+	if plan != nil {
+		err := plan.Validate(freq)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return freq
 	}
-	if 860000000.0 <= f && f <= 920000000.0 {
-		return uint32(f)
+	if 860000000 <= freq && freq <= 920000000 {
+		return freq
 	}
 	log.Fatalf("%s: invalid pump frequency", s)
 	panic("unreachable")
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ecc1/radio"
+	"github.com/ecc1/rfm95"
+)
+
+var (
+	startFlag   = flag.Float64("start", 902.0, "start of sweep `frequency` (in MHz or Hz)")
+	stopFlag    = flag.Float64("stop", 928.0, "end of sweep `frequency` (in MHz or Hz)")
+	stepFlag    = flag.Float64("step", 0.25, "sweep step `size` (in MHz or Hz)")
+	bwFlag      = flag.Uint("bw", 0, "channel bandwidth in `Hz` (0 = same as step)")
+	dwellFlag   = flag.Duration("dwell", 20*time.Millisecond, "sample RSSI for `duration` at each frequency")
+	sweepsFlag  = flag.Int("n", 1, "perform `count` sweeps (0 = until interrupted)")
+	csvFlag     = flag.String("csv", "", "append samples in CSV format to `file` (- for standard output)")
+	displayFlag = flag.String("display", "bar", "terminal display `mode` (bar, waterfall, or none)")
+	minFlag     = flag.Int("min", -120, "RSSI `dBm` at bottom of display scale")
+	maxFlag     = flag.Int("max", -40, "RSSI `dBm` at top of display scale")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Parse()
+	display := displayFunc(*displayFlag)
+	freqs := rfm95.SweepFrequencies(hertz(*startFlag), hertz(*stopFlag), hertz(*stepFlag))
+	if len(freqs) == 0 {
+		log.Fatal("empty frequency range")
+	}
+	bw := uint32(*bwFlag)
+	if bw == 0 {
+		bw = hertz(*stepFlag)
+	}
+	var w *csv.Writer
+	switch *csvFlag {
+	case "":
+	case "-":
+		w = csv.NewWriter(os.Stdout)
+	default:
+		f, err := os.OpenFile(*csvFlag, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = csv.NewWriter(f)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	r.Init(freqs[0])
+	r.SetChannelBW(bw)
+	if *displayFlag == "waterfall" {
+		waterfallHeader(freqs)
+	}
+	for i := 0; *sweepsFlag == 0 || i < *sweepsFlag; i++ {
+		t := time.Now()
+		samples := r.Sweep(freqs, *dwellFlag)
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		if w != nil {
+			writeCSV(w, t, samples)
+		}
+		display(t, samples)
+	}
+}
+
+func writeCSV(w *csv.Writer, t time.Time, samples []rfm95.RSSISample) {
+	ts := t.UTC().Format(time.RFC3339Nano)
+	for _, s := range samples {
+		err := w.Write([]string{
+			ts,
+			strconv.FormatUint(uint64(s.Frequency), 10),
+			strconv.Itoa(s.Max),
+			strconv.Itoa(s.Mean),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	w.Flush()
+	err := w.Error()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func displayFunc(mode string) func(time.Time, []rfm95.RSSISample) {
+	switch mode {
+	case "bar":
+		return barChart
+	case "waterfall":
+		return waterfall
+	case "none":
+		return func(time.Time, []rfm95.RSSISample) {}
+	default:
+		log.Fatalf("unknown display mode %q", mode)
+		panic("unreachable")
+	}
+}
+
+const barWidth = 60
+
+func barChart(t time.Time, samples []rfm95.RSSISample) {
+	fmt.Printf("%s\n", t.Format("15:04:05.000"))
+	for _, s := range samples {
+		n := scale(s.Max, barWidth)
+		fmt.Printf("%s %4d %s\n", radio.MegaHertz(s.Frequency), s.Max, strings.Repeat("#", n))
+	}
+}
+
+// Characters in order of increasing signal strength.
+const shades = " .:-=+*#%@"
+
+func waterfall(t time.Time, samples []rfm95.RSSISample) {
+	line := make([]byte, len(samples))
+	for i, s := range samples {
+		line[i] = shades[scale(s.Max, len(shades)-1)]
+	}
+	fmt.Printf("%s |%s|\n", t.Format("15:04:05.000"), line)
+}
+
+func waterfallHeader(freqs []uint32) {
+	first := radio.MegaHertz(freqs[0])
+	last := radio.MegaHertz(freqs[len(freqs)-1])
+	fmt.Printf("%12s  %s ... %s MHz (%d steps)\n", "", strings.TrimSpace(first), strings.TrimSpace(last), len(freqs))
+}
+
+// scale maps an RSSI value into the range [0, n].
+func scale(rssi int, n int) int {
+	lo, hi := *minFlag, *maxFlag
+	switch {
+	case rssi <= lo:
+		return 0
+	case rssi >= hi:
+		return n
+	default:
+		return (rssi - lo) * n / (hi - lo)
+	}
+}
+
+func hertz(f float64) uint32 {
+	freq, err := rfm95.Hertz(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return freq
+}
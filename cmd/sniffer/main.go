@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ecc1/rfm95"
+	"github.com/ecc1/rfm95/pcap"
+)
+
+var (
+	outputFlag  = flag.String("o", "", "write capture to `file` (default standard output)")
+	minLenFlag  = flag.Int("minlen", 0, "discard packets shorter than `n` bytes")
+	maxLenFlag  = flag.Int("maxlen", 0, "discard packets longer than `n` bytes (0 = no limit)")
+	minRSSIFlag = flag.Int("minrssi", -200, "discard packets weaker than `dBm`")
+	countFlag   = flag.Int("n", 0, "stop after capturing `n` packets (0 = no limit)")
+	quietFlag   = flag.Bool("q", false, "do not log captured packets")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Usage = func() {
+		log.Printf("Usage: %s [options] frequency", flag.CommandLine.Name())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	frequency, err := rfm95.ParseFrequency(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var out io.Writer = os.Stdout
+	if *outputFlag != "" {
+		f, err := os.Create(*outputFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w, err := pcap.NewWriter(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	r.Init(frequency)
+	count := 0
+	for r.Error() == nil {
+		data, rssi := r.Receive(time.Hour)
+		if data == nil || !accept(data, rssi) {
+			continue
+		}
+		p := pcap.Packet{
+			Time:      time.Now(),
+			Frequency: frequency,
+			RSSI:      rssi,
+			FEI:       r.FEI(),
+			Data:      data,
+		}
+		err = w.WritePacket(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !*quietFlag {
+			log.Printf("% X (RSSI = %d, FEI = %d)", data, rssi, p.FEI)
+		}
+		count++
+		if count == *countFlag {
+			return
+		}
+	}
+	log.Fatal(r.Error())
+}
+
+func accept(data []byte, rssi int) bool {
+	if len(data) < *minLenFlag {
+		return false
+	}
+	if *maxLenFlag != 0 && len(data) > *maxLenFlag {
+		return false
+	}
+	return rssi >= *minRSSIFlag
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+var (
+	freqFlag     = flag.Float64("f", 916.6, "transmit at `frequency` (in MHz or Hz)")
+	powerFlag    = flag.Int("power", 0, "set output power to `dBm` (0 = default)")
+	bitrateFlag  = flag.Uint("bitrate", 0, "set bit rate to `bps` (0 = default)")
+	patternFlag  = flag.String("pattern", "carrier", "transmit `pattern` (carrier, square, or pn9)")
+	durationFlag = flag.Duration("d", 0, "stop after `duration` (0 = until interrupted)")
+)
+
+var patterns = map[string]rfm95.TestPattern{
+	"carrier": rfm95.PatternCarrier,
+	"square":  rfm95.PatternSquareWave,
+	"pn9":     rfm95.PatternPN9,
+}
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Parse()
+	pattern, ok := patterns[*patternFlag]
+	if !ok || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	freq, err := rfm95.Hertz(*freqFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Init(freq)
+	if *bitrateFlag != 0 {
+		r.SetBitrate(uint32(*bitrateFlag))
+	}
+	if *powerFlag != 0 {
+		r.SetOutputPower(*powerFlag)
+	}
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	stop := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		if *durationFlag != 0 {
+			select {
+			case <-sig:
+			case <-time.After(*durationFlag):
+			}
+		} else {
+			<-sig
+		}
+		close(stop)
+	}()
+	log.Printf("transmitting %v at %d Hz, %d dBm", pattern, freq, r.OutputPower())
+	r.TransmitTestPattern(pattern, stop)
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	log.Printf("stopped")
+}
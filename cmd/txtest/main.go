@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+var (
+	freqFlag     = flag.Float64("f", 916.6, "transmit at `frequency` (in MHz or Hz)")
+	bitrateFlag  = flag.Uint("bitrate", 0, "set bit rate to `bps` (0 = default)")
+	powerFlag    = flag.Int("power", 0, "set output power to `dBm` (0 = default)")
+	repeatFlag   = flag.Int("n", 1, "send each packet `count` times")
+	intervalFlag = flag.Duration("i", 100*time.Millisecond, "wait `duration` between packets")
+)
+
+func main() {
+	log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
+	flag.Usage = func() {
+		log.Printf("Usage: %s [options] [hex-payload]", flag.CommandLine.Name())
+		log.Printf("If no payload is given, payloads are read from standard input, one per line.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	var payload []byte
+	if flag.NArg() == 1 {
+		payload = parseHex(flag.Arg(0))
+	}
+	freq, err := rfm95.Hertz(*freqFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r := rfm95.Open()
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	defer r.Close()
+	r.Init(freq)
+	if *bitrateFlag != 0 {
+		r.SetBitrate(uint32(*bitrateFlag))
+	}
+	if *powerFlag != 0 {
+		r.SetOutputPower(*powerFlag)
+	}
+	if r.Error() != nil {
+		log.Fatal(r.Error())
+	}
+	if payload != nil {
+		send(r, payload)
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		send(r, parseHex(line))
+	}
+	err = scanner.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+var first = true
+
+func send(r *rfm95.Radio, data []byte) {
+	for i := 0; i < *repeatFlag; i++ {
+		if !first {
+			time.Sleep(*intervalFlag)
+		}
+		first = false
+		r.Send(data)
+		if r.Error() != nil {
+			log.Fatal(r.Error())
+		}
+		log.Printf("sent % X", data)
+	}
+}
+
+// parseHex accepts hex digits with optional whitespace between bytes.
+func parseHex(s string) []byte {
+	s = strings.Join(strings.Fields(s), "")
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		log.Fatalf("invalid payload: %v", err)
+	}
+	return data
+}
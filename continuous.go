@@ -0,0 +1,141 @@
+package rfm95
+
+import (
+	"time"
+)
+
+// In continuous mode (data sheet section 4.2.12), the demodulated data
+// appears on DIO2 and the recovered bit clock on DIO1, bypassing the FIFO,
+// sync word detection, and packet engine.
+
+// Edge is a transition of the demodulated data signal.
+type Edge struct {
+	Time  time.Time
+	Level bool // level after the transition
+}
+
+// Pulse is an interval during which the demodulated data has a constant level.
+type Pulse struct {
+	Level    bool
+	Duration time.Duration
+}
+
+// continuousRegs are the registers changed by StartContinuous,
+// in the order in which they are saved.
+var continuousRegs = []byte{RegPacketConfig2, RegOokPeak, RegDioMapping1}
+
+// StartContinuous puts the radio into continuous receive mode.
+// If bitSync is false, DIO2 carries the raw output of the demodulator,
+// which is preferable for analyzing signals with an unknown bitrate.
+func (r *Radio) StartContinuous(bitSync bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return
+	}
+	r.setMode(StandbyMode)
+	saved := make([]byte, len(continuousRegs))
+	for i, addr := range continuousRegs {
+		saved[i] = r.hw.ReadRegister(addr)
+	}
+	if r.error() != nil {
+		return
+	}
+	// Keep the packet-mode configuration if continuous mode is restarted.
+	if r.continuous == nil {
+		r.continuous = saved
+	}
+	r.hw.WriteRegister(RegPacketConfig2, saved[0]&^PacketMode|ContinuousMode)
+	peak := saved[1] &^ BitSyncOn
+	if bitSync {
+		peak |= BitSyncOn
+	}
+	r.hw.WriteRegister(RegOokPeak, peak)
+	// Map Dclk to DIO1 and Data to DIO2.
+	r.hw.WriteRegister(RegDioMapping1, saved[2]&^(Dio1MappingMask|Dio2MappingMask))
+	r.setMode(ReceiverMode)
+}
+
+// StopContinuous puts the radio to sleep and restores the packet-mode
+// configuration that was in effect before StartContinuous,
+// including the DIO mapping of the receive interrupt.
+func (r *Radio) StopContinuous() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setMode(SleepMode)
+	if r.continuous == nil {
+		cfg := r.hw.ReadRegister(RegPacketConfig2)
+		r.hw.WriteRegister(RegPacketConfig2, cfg|PacketMode)
+		return
+	}
+	for i, addr := range continuousRegs {
+		r.hw.WriteRegister(addr, r.continuous[i])
+	}
+	r.continuous = nil
+}
+
+// CaptureEdges records transitions of the demodulated data on DIO2
+// until the timeout expires or maxEdges transitions have been seen.
+// The radio must be in continuous mode (see StartContinuous).
+// The first element records the initial level, at the start of the capture.
+//
+// Edges are timestamped as they are observed through the GPIO layer,
+// so the resolution is limited by interrupt latency (typically tens of μs).
+// The radio remains locked for the whole capture, so calls from other
+// goroutines, including Receive and Error, block until it returns.
+func (r *Radio) CaptureEdges(timeout time.Duration, maxEdges int) []Edge {
+	r.rxMu.Lock()
+	defer r.rxMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return nil
+	}
+	start := time.Now()
+	level, err := r.hw.StartCapture()
+	// Restore the receive interrupt configuration afterwards.
+	defer func() {
+		if err := r.hw.StopCapture(); err != nil && r.error() == nil {
+			r.setError(err)
+		}
+	}()
+	if err != nil {
+		r.setError(err)
+		return nil
+	}
+	deadline := start.Add(timeout)
+	edges := []Edge{{Time: start, Level: level}}
+	for len(edges) <= maxEdges {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		err = r.hw.WaitEdge(level, remaining)
+		if err != nil {
+			if !isTimeout(err) {
+				r.setError(err)
+			}
+			break
+		}
+		level = !level
+		edges = append(edges, Edge{Time: time.Now(), Level: level})
+	}
+	r.logger.Debug("captured edges", "edges", len(edges)-1, "elapsed", time.Since(start))
+	return edges
+}
+
+// Pulses converts a sequence of edges into the durations of each level.
+// The final level, which has no terminating edge, is omitted.
+func Pulses(edges []Edge) []Pulse {
+	if len(edges) < 2 {
+		return nil
+	}
+	pulses := make([]Pulse, len(edges)-1)
+	for i := range pulses {
+		pulses[i] = Pulse{
+			Level:    edges[i].Level,
+			Duration: edges[i+1].Time.Sub(edges[i].Time),
+		}
+	}
+	return pulses
+}
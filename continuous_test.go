@@ -0,0 +1,101 @@
+package rfm95
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPulses(t *testing.T) {
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(us int) time.Time {
+		return t0.Add(time.Duration(us) * time.Microsecond)
+	}
+	edges := []Edge{
+		{at(0), false},
+		{at(100), true},
+		{at(350), false},
+		{at(400), true},
+	}
+	want := []Pulse{
+		{false, 100 * time.Microsecond},
+		{true, 250 * time.Microsecond},
+		{false, 50 * time.Microsecond},
+	}
+	got := Pulses(edges)
+	if len(got) != len(want) {
+		t.Fatalf("Pulses(%v) == %v, want %v", edges, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("Pulses(%v)[%d] == %v, want %v", edges, i, got[i], want[i])
+		}
+	}
+	if p := Pulses(edges[:1]); p != nil {
+		t.Errorf("Pulses(%v) == %v, want nil", edges[:1], p)
+	}
+}
+
+func TestCaptureEdges(t *testing.T) {
+	cases := []struct {
+		initial  bool
+		levels   []bool
+		maxEdges int
+		want     []bool
+	}{
+		{false, nil, 10, []bool{false}},
+		{false, []bool{true, false, true}, 10, []bool{false, true, false, true}},
+		{true, []bool{false, true, false}, 2, []bool{true, false, true}},
+		// Repeated levels are not edges.
+		{false, []bool{false, true, true, false}, 10, []bool{false, true, false}},
+	}
+	for _, c := range cases {
+		r, hw := newFakeRadio()
+		hw.level = c.initial
+		hw.levels = c.levels
+		edges := r.CaptureEdges(time.Second, c.maxEdges)
+		if r.Error() != nil {
+			t.Errorf("CaptureEdges(%v) error: %v", c.levels, r.Error())
+			continue
+		}
+		got := make([]bool, len(edges))
+		for i, e := range edges {
+			got[i] = e.Level
+			if i > 0 && e.Time.Before(edges[i-1].Time) {
+				t.Errorf("CaptureEdges(%v) edge %d precedes edge %d", c.levels, i, i-1)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("CaptureEdges(%v) levels == %v, want %v", c.levels, got, c.want)
+		}
+		if hw.captures != 1 || hw.capturing {
+			t.Errorf("CaptureEdges(%v) completed %d captures, want 1", c.levels, hw.captures)
+		}
+	}
+}
+
+func TestContinuousRestoresRegisters(t *testing.T) {
+	r, hw := newFakeRadio()
+	hw.regs[RegPacketConfig2] = PacketMode | 0x01
+	hw.regs[RegOokPeak] = BitSyncOn | 0x0C
+	hw.regs[RegDioMapping1] = 3<<Dio2MappingShift | 0x05
+	before := hw.regs
+	r.StartContinuous(false)
+	if hw.regs[RegPacketConfig2]&PacketMode != 0 || hw.regs[RegOokPeak]&BitSyncOn != 0 || hw.regs[RegDioMapping1]&Dio2MappingMask != 0 {
+		t.Errorf("StartContinuous left PacketConfig2 %02X, OokPeak %02X, DioMapping1 %02X",
+			hw.regs[RegPacketConfig2], hw.regs[RegOokPeak], hw.regs[RegDioMapping1])
+	}
+	// A second start must not replace the saved packet-mode configuration.
+	r.StartContinuous(true)
+	r.StopContinuous()
+	if r.Error() != nil {
+		t.Fatal(r.Error())
+	}
+	if hw.regs != before {
+		for i := range before {
+			if hw.regs[i] != before[i] {
+				t.Errorf("register %02X == %02X after StopContinuous, want %02X", i, hw.regs[i], before[i])
+			}
+		}
+	}
+}
@@ -1,3 +1,4 @@
+//go:build !customcs || !386
 // +build !customcs !386
 
 package rfm95
@@ -2,17 +2,13 @@ package rfm95
 
 import (
 	"bytes"
-	"log"
-	"time"
+	"log/slog"
+	"sync"
 
 	"github.com/ecc1/gpio"
 	"github.com/ecc1/radio"
 )
 
-const (
-	hwVersion = 0x0102
-)
-
 type hwFlavor struct{}
 
 // SPIDevice returns the pathname of the radio's SPI device.
@@ -56,45 +52,90 @@ func (hwFlavor) WriteBurstAddress(addr byte) byte {
 }
 
 // Radio represents an open radio device.
+// Its methods are safe for concurrent use; see Receive for the
+// interaction between concurrent sends and receives.
+// The error state, however, is shared: Error reports the most recent
+// error from any goroutine, and another goroutine's next operation
+// may clear it first. Goroutines sharing a radio cannot rely on Error
+// to report the outcome of their own operations.
 type Radio struct {
-	hw            *radio.Hardware
+	mu   sync.Mutex // serializes SPI transactions and mode changes
+	rxMu sync.Mutex // serializes receivers
+
+	hw            Transport
 	receiveBuffer bytes.Buffer
 	txPacket      []byte
+	variant       Variant
+	plan          *ChannelPlan
+	encoding      Encoding
+	fei           int
+	ppm           float64
+	tcxo          bool
+	logger        *slog.Logger
+	stats         Stats
+	continuous    []byte // registers saved by StartContinuous
 	err           error
 }
 
 // Open opens the radio device.
 func Open() *Radio {
-	r := &Radio{hw: radio.Open(hwFlavor{})}
+	t := &spiTransport{Hardware: radio.Open(hwFlavor{})}
+	r := newRadio(t)
 	// NOTE: the RFM95 requires the reset pin to be in input mode
 	_, r.err = gpio.Input(resetPin, true)
-	if r.Error() != nil {
+	if r.error() != nil {
+		r.hw.Close()
+		return r
+	}
+	v := r.version()
+	if r.error() != nil {
 		r.hw.Close()
 		return r
 	}
-	v := r.Version()
-	if r.Error() != nil {
+	c := chipForVersion(v)
+	if c == nil {
 		r.hw.Close()
+		r.setError(radio.HardwareVersionError{Actual: v, Expected: sx1276Version})
 		return r
 	}
-	if v != hwVersion {
+	if r.chip() != c {
+		// The configured variant belongs to the other chip family.
+		r.variant = c.variant
+	}
+	t.interrupt, r.err = gpio.Interrupt(interruptPin, false, "rising")
+	if r.error() != nil {
 		r.hw.Close()
-		r.SetError(radio.HardwareVersionError{Actual: v, Expected: hwVersion})
 		return r
 	}
-	r.txPacket = make([]byte, maxPacketSize+1)
+	r.loadCalibration()
+	r.setTCXO()
 	return r
 }
 
+func newRadio(t Transport) *Radio {
+	return &Radio{
+		hw:       t,
+		txPacket: make([]byte, encodedSize(maxPacketSize)+1),
+		variant:  defaultVariant,
+		tcxo:     boardTCXO,
+		logger:   newLogger(),
+		stats:    newStats(),
+	}
+}
+
 // Close closes the radio device.
 func (r *Radio) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.setMode(SleepMode)
 	r.hw.Close()
 }
 
 // Name returns the radio's name.
 func (r *Radio) Name() string {
-	return "RFM95W"
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.variant.String()
 }
 
 // Device returns the pathname of the radio's device.
@@ -104,32 +145,57 @@ func (*Radio) Device() string {
 
 // Version returns the radio's hardware version.
 func (r *Radio) Version() uint16 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.version()
+}
+
+func (r *Radio) version() uint16 {
 	v := r.hw.ReadRegister(RegVersion)
 	return uint16(v>>4)<<8 | uint16(v&0xF)
 }
 
-// Reset resets the radio device.  See section 7.2.2 of data sheet.
-// NOTE: the RFM95 requires the reset pin to be in input mode
-// except while resetting the chip, unlike the RFM69 for example.
+// Reset resets the radio device.
 func (r *Radio) Reset() {
-	_, err := gpio.Output(resetPin, true, true)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reset()
+}
+
+func (r *Radio) reset() {
+	err := r.hw.Reset()
 	if err != nil {
-		log.Printf("Reset: gpio.Output: %s", err)
+		r.logger.Error("reset failed", "err", err)
+		r.setError(err)
+		return
 	}
-	time.Sleep(100 * time.Microsecond)
-	_, r.err = gpio.Input(resetPin, true)
-	time.Sleep(5 * time.Millisecond)
+	// The clock source reverts to the crystal oscillator on reset.
+	r.setTCXO()
 }
 
 // Init initializes the radio device.
+// It fails without changing the radio if the frequency is not allowed
+// by the radio's variant and active channel plan.
 func (r *Radio) Init(frequency uint32) {
-	r.Reset()
-	r.InitRF(frequency)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.validFrequency(frequency) {
+		return
+	}
+	r.reset()
+	r.initRF(frequency)
 	r.setMode(SleepMode)
 }
 
 // Error returns the error state of the radio device.
+// It is only reliable when the radio is used by a single goroutine (see Radio).
 func (r *Radio) Error() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.error()
+}
+
+func (r *Radio) error() error {
 	err := r.hw.Error()
 	if err != nil {
 		return err
@@ -139,11 +205,37 @@ func (r *Radio) Error() error {
 
 // SetError sets the error state of the radio device.
 func (r *Radio) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setError(err)
+}
+
+func (r *Radio) setError(err error) {
 	r.hw.SetError(err)
 	r.err = err
 }
 
-// Hardware returns the radio's hardware information.
+// Hardware returns the radio's hardware information,
+// or nil if the radio does not access the chip through SPI (see Replay).
+// Operations performed through it are not synchronized with the radio's methods,
+// and are not recorded.
 func (r *Radio) Hardware() *radio.Hardware {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hw := r.hw
+	if t, ok := hw.(*recorder); ok {
+		hw = t.Transport
+	}
+	if t, ok := hw.(*spiTransport); ok {
+		return t.Hardware
+	}
+	return nil
+}
+
+// Transport returns the transport through which the radio accesses the chip.
+// Operations performed through it are not synchronized with the radio's methods.
+func (r *Radio) Transport() Transport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.hw
 }
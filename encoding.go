@@ -0,0 +1,226 @@
+package rfm95
+
+import (
+	"fmt"
+)
+
+// Encoding is a DC-free data encoding.
+type Encoding byte
+
+// Encodings, with the values used in the RegPacketConfig1 DcFree field.
+const (
+	EncodingNone       Encoding = 0
+	EncodingManchester Encoding = 1
+	EncodingWhitening  Encoding = 2
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingNone:
+		return "None"
+	case EncodingManchester:
+		return "Manchester"
+	case EncodingWhitening:
+		return "Whitening"
+	default:
+		return fmt.Sprintf("Unknown Encoding (%d)", e)
+	}
+}
+
+func (e Encoding) valid() bool {
+	return e <= EncodingWhitening
+}
+
+// PacketEncoding returns the DC-free encoding used by the hardware packet engine.
+func (r *Radio) PacketEncoding() Encoding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Encoding((r.hw.ReadRegister(RegPacketConfig1) & DcFreeMask) >> DcFreeShift)
+}
+
+// SetPacketEncoding sets the DC-free encoding used by the hardware packet engine
+// for fixed and variable length packets. Send and Receive use the
+// unlimited length packet format instead; see SetEncoding.
+func (r *Radio) SetPacketEncoding(e Encoding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !e.valid() {
+		r.setError(fmt.Errorf("invalid encoding %d", e))
+		return
+	}
+	cfg := r.hw.ReadRegister(RegPacketConfig1)
+	r.hw.WriteRegister(RegPacketConfig1, cfg&^DcFreeMask|byte(e)<<DcFreeShift)
+}
+
+// Encoding returns the encoding applied in software by Send and Receive.
+func (r *Radio) Encoding() Encoding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.encoding
+}
+
+// SetEncoding sets the encoding applied in software by Send and Receive.
+// Both ends of a link must use the same encoding.
+func (r *Radio) SetEncoding(e Encoding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !e.valid() {
+		r.setError(fmt.Errorf("invalid encoding %d", e))
+		return
+	}
+	r.encoding = e
+}
+
+// encodedSize returns the maximum size of an encoded n-byte packet.
+func encodedSize(n int) int {
+	// Manchester encoding doubles the size;
+	// whitening adds a trailer and one stuffing byte per 254 data bytes.
+	m := 2 * n
+	if w := n + n/254 + 2; w > m {
+		m = w
+	}
+	return m
+}
+
+// encode applies the given encoding to a packet.
+// The result never contains a zero byte, which would end the packet.
+func encode(e Encoding, data []byte) []byte {
+	switch e {
+	case EncodingManchester:
+		return manchesterEncode(data)
+	case EncodingWhitening:
+		p := stuffZeros(whiten(data))
+		return append(p, whiteningTrailer)
+	default:
+		return data
+	}
+}
+
+// decode reverses the given encoding.
+func decode(e Encoding, data []byte) ([]byte, error) {
+	switch e {
+	case EncodingManchester:
+		return manchesterDecode(data)
+	case EncodingWhitening:
+		n := len(data)
+		if n == 0 || data[n-1] != whiteningTrailer {
+			return nil, fmt.Errorf("missing whitening trailer")
+		}
+		p, err := unstuffZeros(data[:n-1])
+		if err != nil {
+			return nil, err
+		}
+		return whiten(p), nil
+	default:
+		return data, nil
+	}
+}
+
+// Manchester encoding represents each 1 bit as 10 and each 0 bit as 01,
+// most significant bit first (data sheet section 4.2.12.2).
+func manchesterEncode(data []byte) []byte {
+	p := make([]byte, 2*len(data))
+	for i, b := range data {
+		w := uint16(0)
+		for j := 7; j >= 0; j-- {
+			w <<= 2
+			if b&(1<<uint(j)) != 0 {
+				w |= 2
+			} else {
+				w |= 1
+			}
+		}
+		p[2*i] = byte(w >> 8)
+		p[2*i+1] = byte(w)
+	}
+	return p
+}
+
+func manchesterDecode(data []byte) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("odd Manchester-encoded length (%d)", len(data))
+	}
+	p := make([]byte, len(data)/2)
+	for i := range p {
+		w := uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		b := byte(0)
+		for j := 7; j >= 0; j-- {
+			b <<= 1
+			switch (w >> (2 * uint(j))) & 3 {
+			case 2:
+				b |= 1
+			case 1:
+			default:
+				return nil, fmt.Errorf("invalid Manchester symbol in byte %d (%02X %02X)", i, data[2*i], data[2*i+1])
+			}
+		}
+		p[i] = b
+	}
+	return p, nil
+}
+
+// pn9 generates the PN9 sequence (x^9 + x^5 + 1, seed 0x1FF)
+// used for data whitening (data sheet section 4.2.12.3).
+type pn9 struct {
+	state uint16
+}
+
+func newPN9() *pn9 {
+	return &pn9{state: 0x1FF}
+}
+
+func (p *pn9) next() byte {
+	b := byte(p.state)
+	for i := 0; i < 8; i++ {
+		p.state = p.state>>1 | ((p.state^p.state>>5)&1)<<8
+	}
+	return b
+}
+
+// whiten XORs data with the PN9 sequence. It is its own inverse.
+func whiten(data []byte) []byte {
+	g := newPN9()
+	p := make([]byte, len(data))
+	for i, b := range data {
+		p[i] = b ^ g.next()
+	}
+	return p
+}
+
+// Whitened data can contain zero bytes, which would end the packet,
+// so it is stuffed using consistent overhead byte stuffing (COBS).
+// The trailer byte protects the last data byte from end-of-packet glitch removal.
+const whiteningTrailer = 0x55
+
+func stuffZeros(data []byte) []byte {
+	p := make([]byte, 1, len(data)+len(data)/254+2)
+	code := 0
+	for _, b := range data {
+		if b != 0 {
+			p = append(p, b)
+		}
+		if b == 0 || len(p)-code == 0xFF {
+			p[code] = byte(len(p) - code)
+			code = len(p)
+			p = append(p, 0)
+		}
+	}
+	p[code] = byte(len(p) - code)
+	return p
+}
+
+func unstuffZeros(data []byte) ([]byte, error) {
+	p := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		if n == 0 || i+n > len(data) {
+			return nil, fmt.Errorf("invalid stuffed data at byte %d", i)
+		}
+		p = append(p, data[i+1:i+n]...)
+		i += n
+		if n != 0xFF && i < len(data) {
+			p = append(p, 0)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,74 @@
+package rfm95
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestManchester(t *testing.T) {
+	cases := []struct {
+		data    []byte
+		encoded []byte
+	}{
+		{[]byte{0x00}, []byte{0x55, 0x55}},
+		{[]byte{0xFF}, []byte{0xAA, 0xAA}},
+		{[]byte{0x0F, 0xA5}, []byte{0x55, 0xAA, 0x99, 0x66}},
+	}
+	for _, c := range cases {
+		e := manchesterEncode(c.data)
+		if !bytes.Equal(e, c.encoded) {
+			t.Errorf("manchesterEncode(% X) == % X, want % X", c.data, e, c.encoded)
+		}
+		d, err := manchesterDecode(e)
+		if err != nil || !bytes.Equal(d, c.data) {
+			t.Errorf("manchesterDecode(% X) == % X, %v, want % X", e, d, err, c.data)
+		}
+	}
+	for _, e := range [][]byte{{0x55}, {0x55, 0x54}, {0xFF, 0xAA}} {
+		_, err := manchesterDecode(e)
+		if err == nil {
+			t.Errorf("manchesterDecode(% X) should have failed", e)
+		}
+	}
+}
+
+func TestPN9(t *testing.T) {
+	want := []byte{0xFF, 0xE1, 0x1D, 0x9A, 0xED, 0x85, 0x33, 0x24}
+	got := whiten(make([]byte, len(want)))
+	if !bytes.Equal(got, want) {
+		t.Errorf("PN9 sequence == % X, want % X", got, want)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	packets := [][]byte{
+		{},
+		{0x00},
+		{0xFF, 0xE1, 0x1D},
+		make([]byte, maxPacketSize),
+		bytes.Repeat([]byte{0x80}, maxPacketSize),
+	}
+	for i := 0; i < 100; i++ {
+		p := make([]byte, 1+rand.Intn(maxPacketSize))
+		rand.Read(p)
+		packets = append(packets, p)
+	}
+	for _, e := range []Encoding{EncodingNone, EncodingManchester, EncodingWhitening} {
+		for _, p := range packets {
+			enc := encode(e, p)
+			if e != EncodingNone {
+				if bytes.IndexByte(enc, 0) != -1 {
+					t.Errorf("%v: encode(% X) == % X contains a zero byte", e, p, enc)
+				}
+				if len(enc) > encodedSize(len(p)) {
+					t.Errorf("%v: encode(% X) has length %d, want at most %d", e, p, len(enc), encodedSize(len(p)))
+				}
+			}
+			dec, err := decode(e, enc)
+			if err != nil || !bytes.Equal(dec, p) {
+				t.Errorf("%v: decode(% X) == % X, %v, want % X", e, enc, dec, err, p)
+			}
+		}
+	}
+}
@@ -1,9 +1,12 @@
 module github.com/ecc1/rfm95
 
-go 1.13
+go 1.21
 
 require (
 	github.com/ecc1/gpio v0.0.0-20230226182448-afe57342d422
 	github.com/ecc1/radio v0.0.0-20230226182625-a0856dd1b465
-	github.com/ecc1/spi v0.0.0-20230226182530-b0f4c20d714a // indirect
+	golang.org/x/sys v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/ecc1/spi v0.0.0-20230226182530-b0f4c20d714a // indirect
@@ -0,0 +1,132 @@
+package rfm95
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+)
+
+// SetFastHop enables or disables fast frequency hopping (data sheet section 2.3.2).
+// When enabled, a write to RegFrfLsb retunes the PLL immediately,
+// without going through the frequency synthesizer state.
+func (r *Radio) SetFastHop(enable bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setFastHop(enable)
+}
+
+func (r *Radio) setFastHop(enable bool) {
+	v := r.hw.ReadRegister(r.chip().regPllHop)
+	if enable {
+		v |= FastHopOn
+	} else {
+		v &^= FastHopOn
+	}
+	r.hw.WriteRegister(r.chip().regPllHop, v)
+}
+
+// FastHop reports whether fast frequency hopping is enabled.
+func (r *Radio) FastHop() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fastHop()
+}
+
+func (r *Radio) fastHop() bool {
+	return r.hw.ReadRegister(r.chip().regPllHop)&FastHopOn != 0
+}
+
+// Hop retunes the radio to the given frequency, in Hertz,
+// without leaving the transmit or receive state.
+// Fast frequency hopping is enabled if necessary.
+// Hops between the low and high frequency bands are rejected,
+// since they require a change to LowFrequencyModeOn; use SetFrequency instead.
+func (r *Radio) Hop(freq uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return
+	}
+	if !r.validFrequency(freq) {
+		return
+	}
+	if r.chip().hasLowFrequencyMode {
+		low := r.hw.ReadRegister(RegOpMode)&LowFrequencyModeOn != 0
+		if low != lowFrequency(freq) {
+			r.setError(fmt.Errorf("%d Hz: cannot hop across the %d Hz band boundary", freq, lowFrequencyLimit))
+			return
+		}
+	}
+	if !r.fastHop() {
+		r.setFastHop(true)
+	}
+	// The burst write ends with RegFrfLsb, which triggers the frequency change.
+	r.hw.WriteBurst(RegFrfMsb, frequencyToRegisters(freq, r.xo()))
+	if r.debugging() {
+		r.logger.Debug("hopped", "frequency", freq, "state", r.state())
+	}
+}
+
+// HopNext retunes the radio to the next channel in the given sequence.
+func (r *Radio) HopNext(seq *HopSequence) {
+	r.Hop(seq.Next())
+}
+
+// Channels returns n evenly spaced channel frequencies, in Hertz,
+// starting at first and separated by spacing.
+func Channels(first uint32, spacing uint32, n int) []uint32 {
+	c := make([]uint32, n)
+	for i := range c {
+		c[i] = first + uint32(i)*spacing
+	}
+	return c
+}
+
+// HopSequence is a repeating sequence of channel frequencies.
+// The zero value is not a valid sequence; use NewHopSequence.
+type HopSequence struct {
+	channels []uint32
+	next     int
+}
+
+// NewHopSequence returns a sequence that visits the given channels in order.
+func NewHopSequence(channels []uint32) *HopSequence {
+	if len(channels) == 0 {
+		log.Panicf("empty hop sequence")
+	}
+	c := make([]uint32, len(channels))
+	copy(c, channels)
+	return &HopSequence{channels: c}
+}
+
+// NewRandomHopSequence returns a sequence that visits each of the given channels
+// once per cycle, in a pseudo-random order determined by seed.
+// Both ends of a link must use the same channels and seed.
+func NewRandomHopSequence(channels []uint32, seed int64) *HopSequence {
+	seq := NewHopSequence(channels)
+	c := seq.channels
+	rand.New(rand.NewSource(seed)).Shuffle(len(c), func(i, j int) {
+		c[i], c[j] = c[j], c[i]
+	})
+	return seq
+}
+
+// Next returns the next frequency in the sequence.
+func (seq *HopSequence) Next() uint32 {
+	if len(seq.channels) == 0 {
+		log.Panicf("Next: hop sequence was not created by NewHopSequence")
+	}
+	f := seq.channels[seq.next]
+	seq.next = (seq.next + 1) % len(seq.channels)
+	return f
+}
+
+// Reset restarts the sequence from its first channel.
+func (seq *HopSequence) Reset() {
+	seq.next = 0
+}
+
+// Len returns the number of channels in the sequence.
+func (seq *HopSequence) Len() int {
+	return len(seq.channels)
+}
@@ -0,0 +1,99 @@
+package rfm95
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestChannels(t *testing.T) {
+	c := Channels(902300000, 200000, 64)
+	if len(c) != 64 {
+		t.Fatalf("len(Channels) == %d, want 64", len(c))
+	}
+	if c[0] != 902300000 || c[63] != 914900000 {
+		t.Errorf("Channels == %d .. %d, want 902300000 .. 914900000", c[0], c[63])
+	}
+}
+
+func TestHopSequence(t *testing.T) {
+	c := Channels(902300000, 200000, 8)
+	seq := NewHopSequence(c)
+	for cycle := 0; cycle < 2; cycle++ {
+		for i, f := range c {
+			n := seq.Next()
+			if n != f {
+				t.Errorf("cycle %d: Next() == %d, want %d", cycle, n, c[i])
+			}
+		}
+	}
+}
+
+func TestRandomHopSequence(t *testing.T) {
+	c := Channels(902300000, 200000, 50)
+	seq1 := NewRandomHopSequence(c, 42)
+	seq2 := NewRandomHopSequence(c, 42)
+	got := make([]uint32, len(c))
+	for i := range got {
+		got[i] = seq1.Next()
+		if f := seq2.Next(); f != got[i] {
+			t.Errorf("sequences with equal seeds differ at %d: %d != %d", i, got[i], f)
+		}
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	for i, f := range c {
+		if got[i] != f {
+			t.Fatalf("random sequence does not visit each channel once: %v", got)
+		}
+	}
+	seq1.Reset()
+	seq2.Reset()
+	if seq1.Next() != seq2.Next() {
+		t.Errorf("sequences differ after Reset")
+	}
+}
+
+func TestZeroHopSequence(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Next() on zero HopSequence did not panic")
+		}
+	}()
+	var seq HopSequence
+	seq.Next()
+}
+
+func TestHop(t *testing.T) {
+	cases := []struct {
+		from, to uint32
+		ok       bool
+	}{
+		{902300000, 914900000, true},
+		{433050000, 434790000, true},
+		{433050000, 915000000, false},
+		{915000000, 433050000, false},
+	}
+	for _, c := range cases {
+		r, hw := newFakeRadio()
+		r.SetFrequency(c.from)
+		if r.Error() != nil {
+			t.Fatalf("SetFrequency(%d) error: %v", c.from, r.Error())
+		}
+		opMode := hw.regs[RegOpMode]
+		r.Hop(c.to)
+		if (r.Error() == nil) != c.ok {
+			t.Errorf("Hop(%d) from %d: error %v, want ok == %v", c.to, c.from, r.Error(), c.ok)
+			continue
+		}
+		if hw.regs[RegOpMode] != opMode {
+			t.Errorf("Hop(%d) from %d changed RegOpMode from %02X to %02X", c.to, c.from, opMode, hw.regs[RegOpMode])
+		}
+		want := c.from
+		if c.ok {
+			want = c.to
+		}
+		f := registersToFrequency(hw.regs[RegFrfMsb:RegFrfMsb+3], r.xo())
+		if f+62 < want || f > want+62 {
+			t.Errorf("Hop(%d) from %d: frequency %d, want %d", c.to, c.from, f, want)
+		}
+	}
+}
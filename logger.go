@@ -0,0 +1,52 @@
+package rfm95
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Diagnostics are logged at the following levels:
+//   - Debug: mode changes, FIFO activity, and packet events
+//   - Warn: conditions that cause data to be discarded or delayed
+//   - Error: hardware failures that are not reported through Error
+
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetDefaultLogger sets the logger used by radios opened afterwards.
+// A nil logger restores the default, which is slog.Default().
+func SetDefaultLogger(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
+
+func newLogger() *slog.Logger {
+	l := defaultLogger.Load()
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
+
+// Logger returns the logger used for the radio's diagnostics.
+func (r *Radio) Logger() *slog.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logger
+}
+
+// SetLogger sets the logger used for the radio's diagnostics.
+// A nil logger restores the default (see SetDefaultLogger).
+func (r *Radio) SetLogger(l *slog.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l == nil {
+		l = newLogger()
+	}
+	r.logger = l
+}
+
+// debugging reports whether debug messages will be logged,
+// so that arguments requiring SPI transactions can be omitted otherwise.
+func (r *Radio) debugging() bool {
+	return r.logger.Enabled(context.Background(), slog.LevelDebug)
+}
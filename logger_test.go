@@ -0,0 +1,36 @@
+package rfm95
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	debug := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	info := slog.New(slog.NewTextHandler(&buf, nil))
+	defer SetDefaultLogger(nil)
+	cases := []struct {
+		dflt      *slog.Logger
+		logger    *slog.Logger
+		want      *slog.Logger
+		debugging bool
+	}{
+		{nil, nil, slog.Default(), false},
+		{debug, nil, debug, true},
+		{nil, info, info, false},
+		{info, debug, debug, true},
+	}
+	for i, c := range cases {
+		SetDefaultLogger(c.dflt)
+		r := &Radio{}
+		r.SetLogger(c.logger)
+		if r.Logger() != c.want {
+			t.Errorf("case %d: Logger() returned the wrong logger", i)
+		}
+		if r.debugging() != c.debugging {
+			t.Errorf("case %d: debugging() == %v, want %v", i, r.debugging(), c.debugging)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package medtronic
+
+// CRC8 computes the 8-bit CRC (polynomial 0x9B) used in pump packets.
+func CRC8(msg []byte) byte {
+	crc := byte(0)
+	for _, b := range msg {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x9B
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// CRC16 computes the 16-bit CCITT CRC (polynomial 0x1021, initial value 0xFFFF)
+// used in pump history pages and long packets.
+func CRC16(msg []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range msg {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,37 @@
+package medtronic
+
+import (
+	"testing"
+)
+
+var check = []byte("123456789")
+
+func TestCRC8(t *testing.T) {
+	crc := CRC8(check)
+	if crc != 0xEA {
+		t.Errorf("CRC8(%q) == %02X, want EA", check, crc)
+	}
+}
+
+func TestCRC16(t *testing.T) {
+	crc := CRC16(check)
+	if crc != 0x29B1 {
+		t.Errorf("CRC16(%q) == %04X, want 29B1", check, crc)
+	}
+}
+
+func TestPacket(t *testing.T) {
+	msg := []byte{0xA7, 0x12, 0x34, 0x56, 0x8D, 0x00}
+	for _, c := range []Checksum{ChecksumCRC8, ChecksumCRC16} {
+		data := EncodePacket(msg, c)
+		p, err := DecodePacket(data, c)
+		if err != nil || string(p) != string(msg) {
+			t.Errorf("DecodePacket(EncodePacket(% X)) == % X, %v", msg, p, err)
+		}
+		bad := Encode4b6b(append(append([]byte{}, msg...), 0, 0)[:len(msg)+c.size()])
+		_, err = DecodePacket(bad, c)
+		if _, ok := err.(CRCError); !ok {
+			t.Errorf("DecodePacket(% X) returned %v, want CRCError", bad, err)
+		}
+	}
+}
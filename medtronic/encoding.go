@@ -0,0 +1,95 @@
+// Package medtronic implements the 4b6b line coding and packet checksums
+// used by Medtronic insulin pumps.
+package medtronic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Each 4-bit nibble is transmitted as a 6-bit symbol.
+var encode4b6b = []byte{
+	0x15, 0x31, 0x32, 0x23, 0x34, 0x25, 0x26, 0x16,
+	0x1A, 0x19, 0x2A, 0x0B, 0x2C, 0x0D, 0x0E, 0x1C,
+}
+
+var decode6b4b = make(map[byte]byte, len(encode4b6b))
+
+func init() {
+	for i, v := range encode4b6b {
+		decode6b4b[v] = byte(i)
+	}
+}
+
+// Encode4b6b returns the 4b6b encoding of data.
+// If data has an odd number of bytes, the final nibble is padded with 0101.
+func Encode4b6b(data []byte) []byte {
+	n := len(data)
+	buf := make([]byte, 0, (3*n+1)/2)
+	acc := uint(0)
+	bits := uint(0)
+	for _, b := range data {
+		acc = acc<<12 | uint(encode4b6b[b>>4])<<6 | uint(encode4b6b[b&0xF])
+		bits += 12
+		for bits >= 8 {
+			bits -= 8
+			buf = append(buf, byte(acc>>bits))
+		}
+	}
+	if bits != 0 {
+		buf = append(buf, byte(acc<<(8-bits))|0x5)
+	}
+	return buf
+}
+
+// SymbolError records an invalid 6-bit symbol.
+type SymbolError struct {
+	Position int // index of the symbol in the encoded data
+	Symbol   byte
+}
+
+// DecodingError lists the invalid symbols found by Decode6b4b.
+type DecodingError []SymbolError
+
+func (e DecodingError) Error() string {
+	s := make([]string, len(e))
+	for i, v := range e {
+		s[i] = fmt.Sprintf("%02X at %d", v.Symbol, v.Position)
+	}
+	return fmt.Sprintf("4b6b decoding: %d invalid symbols (%s)", len(e), strings.Join(s, ", "))
+}
+
+// Decode6b4b decodes 4b6b-encoded data.
+// Any trailing padding or incomplete byte is ignored.
+// If some symbols are invalid, the corresponding nibbles are zero
+// and the error is a DecodingError.
+func Decode6b4b(data []byte) ([]byte, error) {
+	n := len(data) * 8 / 12
+	buf := make([]byte, 0, n)
+	var errs DecodingError
+	acc := uint(0)
+	bits := uint(0)
+	pos := 0
+	nibble := func(sym byte) byte {
+		v, ok := decode6b4b[sym]
+		if !ok {
+			errs = append(errs, SymbolError{Position: pos, Symbol: sym})
+		}
+		pos++
+		return v
+	}
+	for _, b := range data {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		if bits >= 12 && len(buf) < n {
+			bits -= 12
+			hi := nibble(byte(acc>>(bits+6)) & 0x3F)
+			lo := nibble(byte(acc>>bits) & 0x3F)
+			buf = append(buf, hi<<4|lo)
+		}
+	}
+	if errs != nil {
+		return buf, errs
+	}
+	return buf, nil
+}
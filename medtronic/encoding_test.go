@@ -0,0 +1,47 @@
+package medtronic
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	cases := []struct {
+		decoded []byte
+		encoded []byte
+	}{
+		{[]byte{}, []byte{}},
+		{[]byte{0xA7}, []byte{0xA9, 0x65}},
+		{[]byte{0xA7, 0x12}, []byte{0xA9, 0x6C, 0x72}},
+		{[]byte{0xA7, 0x12, 0x89, 0x86, 0x5D, 0x00}, []byte{0xA9, 0x6C, 0x72, 0x69, 0x96, 0xA6, 0x94, 0xD5, 0x55}},
+	}
+	for _, c := range cases {
+		e := Encode4b6b(c.decoded)
+		if !bytes.Equal(e, c.encoded) {
+			t.Errorf("Encode4b6b(% X) == % X, want % X", c.decoded, e, c.encoded)
+		}
+		d, err := Decode6b4b(c.encoded)
+		if err != nil || !bytes.Equal(d, c.decoded) {
+			t.Errorf("Decode6b4b(% X) == % X, %v, want % X", c.encoded, d, err, c.decoded)
+		}
+	}
+}
+
+func TestDecodingError(t *testing.T) {
+	// Symbols 2A 00 15 3F, of which 00 and 3F are invalid.
+	data := []byte{0xA8, 0x05, 0x7F}
+	_, err := Decode6b4b(data)
+	e, ok := err.(DecodingError)
+	if !ok {
+		t.Fatalf("Decode6b4b(% X) returned %v, want DecodingError", data, err)
+	}
+	want := DecodingError{{Position: 1, Symbol: 0x00}, {Position: 3, Symbol: 0x3F}}
+	if len(e) != len(want) {
+		t.Fatalf("Decode6b4b(% X) errors == %v, want %v", data, e, want)
+	}
+	for i := range e {
+		if e[i] != want[i] {
+			t.Errorf("Decode6b4b(% X) error %d == %v, want %v", data, i, e[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package medtronic
+
+import (
+	"github.com/ecc1/radio"
+)
+
+// Ensure that *Radio implements the radio.Interface interface.
+var _ radio.Interface = (*Radio)(nil)
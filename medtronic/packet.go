@@ -0,0 +1,68 @@
+package medtronic
+
+import (
+	"fmt"
+)
+
+// Checksum identifies the CRC appended to a packet.
+type Checksum int
+
+// Packet checksums.
+const (
+	ChecksumCRC8 Checksum = iota
+	ChecksumCRC16
+)
+
+func (c Checksum) size() int {
+	if c == ChecksumCRC16 {
+		return 2
+	}
+	return 1
+}
+
+func (c Checksum) compute(msg []byte) []byte {
+	if c == ChecksumCRC16 {
+		crc := CRC16(msg)
+		return []byte{byte(crc >> 8), byte(crc)}
+	}
+	return []byte{CRC8(msg)}
+}
+
+// CRCError indicates a packet whose checksum does not match its contents.
+type CRCError struct {
+	Packet   []byte
+	Computed []byte
+}
+
+func (e CRCError) Error() string {
+	n := len(e.Packet) - len(e.Computed)
+	return fmt.Sprintf("CRC should be % X, not % X", e.Computed, e.Packet[n:])
+}
+
+// EncodePacket appends the checksum to msg and returns its 4b6b encoding.
+func EncodePacket(msg []byte, c Checksum) []byte {
+	p := make([]byte, len(msg), len(msg)+c.size())
+	copy(p, msg)
+	return Encode4b6b(append(p, c.compute(msg)...))
+}
+
+// DecodePacket decodes a 4b6b-encoded packet, verifies its checksum,
+// and returns the payload without the checksum.
+func DecodePacket(data []byte, c Checksum) ([]byte, error) {
+	p, err := Decode6b4b(data)
+	if err != nil {
+		return nil, err
+	}
+	n := len(p) - c.size()
+	if n < 0 {
+		return nil, fmt.Errorf("%d-byte packet is too short", len(p))
+	}
+	msg := p[:n]
+	crc := c.compute(msg)
+	for i, b := range crc {
+		if p[n+i] != b {
+			return nil, CRCError{Packet: p, Computed: crc}
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,75 @@
+package medtronic
+
+import (
+	"time"
+
+	"github.com/ecc1/radio"
+)
+
+// Radio wraps a radio device so that Send and Receive
+// operate on decoded payloads. The underlying device
+// provides the null-terminated framing.
+type Radio struct {
+	radio.Interface
+	checksum Checksum
+}
+
+// crcRecorder is implemented by radios that count checksum failures,
+// such as *rfm95.Radio.
+type crcRecorder interface {
+	RecordCRCError()
+}
+
+// NewRadio returns a Medtronic packet interface to the given radio,
+// using the CRC8 checksum.
+func NewRadio(r radio.Interface) *Radio {
+	return &Radio{Interface: r, checksum: ChecksumCRC8}
+}
+
+// Checksum returns the checksum used for packets.
+func (r *Radio) Checksum() Checksum {
+	return r.checksum
+}
+
+// SetChecksum sets the checksum used for packets.
+func (r *Radio) SetChecksum(c Checksum) {
+	r.checksum = c
+}
+
+// Send appends the checksum to the given payload,
+// encodes it, and transmits it.
+func (r *Radio) Send(msg []byte) {
+	r.Interface.Send(EncodePacket(msg, r.checksum))
+}
+
+// Receive listens with the given timeout for an incoming packet.
+// It returns the decoded payload and the associated RSSI.
+// If the packet cannot be decoded or has an incorrect checksum,
+// the radio's error is set to a DecodingError or CRCError.
+func (r *Radio) Receive(timeout time.Duration) ([]byte, int) {
+	data, rssi := r.Interface.Receive(timeout)
+	if data == nil || r.Error() != nil {
+		return nil, rssi
+	}
+	msg, err := DecodePacket(data, r.checksum)
+	if err != nil {
+		if c, ok := r.Interface.(crcRecorder); ok {
+			if _, isCRC := err.(CRCError); isCRC {
+				c.RecordCRCError()
+			}
+		}
+		r.SetError(err)
+		return nil, rssi
+	}
+	return msg, rssi
+}
+
+// SendAndReceive transmits the given payload,
+// then listens with the given timeout for an incoming packet.
+func (r *Radio) SendAndReceive(msg []byte, timeout time.Duration) ([]byte, int) {
+	r.Send(msg)
+	if r.Error() != nil {
+		return nil, 0
+	}
+	return r.Receive(timeout)
+}
@@ -0,0 +1,151 @@
+// Package pcap reads and writes packet captures in the libpcap file format.
+//
+// Each record starts with a fixed-size radio header, followed by the packet data:
+//
+//	offset  size  field
+//	0       1     header version (0)
+//	1       1     header length (12)
+//	2       2     RSSI in dBm (signed)
+//	4       4     frequency in Hz
+//	8       4     frequency error (FEI) in Hz (signed)
+//
+// All fields are big-endian. The records use the DLT_USER0 link type,
+// so Wireshark can be told to skip the header by adding an entry
+// with header size 12 to its "DLT_USER" protocol preferences.
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	magic        = 0xA1B2C3D4 // microsecond timestamps
+	versionMajor = 2
+	versionMinor = 4
+	snapLen      = 65535
+
+	// LinkType is the link-layer header type used in capture files (DLT_USER0).
+	LinkType = 147
+
+	headerVersion = 0
+	// HeaderSize is the size of the radio header preceding each packet.
+	HeaderSize = 12
+)
+
+// Packet is a captured packet with its reception metadata.
+type Packet struct {
+	Time      time.Time
+	Frequency uint32 // Hz
+	RSSI      int    // dBm
+	FEI       int    // Hz
+	Data      []byte
+}
+
+// Writer writes packets to a capture file.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the file header to w and returns a Writer.
+func NewWriter(w io.Writer) (*Writer, error) {
+	var h [24]byte
+	binary.LittleEndian.PutUint32(h[0:], magic)
+	binary.LittleEndian.PutUint16(h[4:], versionMajor)
+	binary.LittleEndian.PutUint16(h[6:], versionMinor)
+	// Bytes 8-15 (time zone and timestamp accuracy) are zero.
+	binary.LittleEndian.PutUint32(h[16:], snapLen)
+	binary.LittleEndian.PutUint32(h[20:], LinkType)
+	_, err := w.Write(h[:])
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// WritePacket writes a packet record.
+func (w *Writer) WritePacket(p Packet) error {
+	n := HeaderSize + len(p.Data)
+	if n > snapLen {
+		return fmt.Errorf("%d-byte packet is too large", len(p.Data))
+	}
+	buf := make([]byte, 16+n)
+	usec := p.Time.UnixNano() / 1000
+	binary.LittleEndian.PutUint32(buf[0:], uint32(usec/1000000))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(usec%1000000))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(n))
+	binary.LittleEndian.PutUint32(buf[12:], uint32(n))
+	h := buf[16:]
+	h[0] = headerVersion
+	h[1] = HeaderSize
+	binary.BigEndian.PutUint16(h[2:], uint16(int16(p.RSSI)))
+	binary.BigEndian.PutUint32(h[4:], p.Frequency)
+	binary.BigEndian.PutUint32(h[8:], uint32(int32(p.FEI)))
+	copy(h[HeaderSize:], p.Data)
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// Reader reads packets from a capture file written by Writer.
+type Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+}
+
+// NewReader reads the file header from r and returns a Reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	var h [24]byte
+	_, err := io.ReadFull(r, h[:])
+	if err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(h[0:]) == magic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(h[0:]) == magic:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a pcap file (magic number % X)", h[0:4])
+	}
+	if t := order.Uint32(h[20:]); t != LinkType {
+		return nil, fmt.Errorf("unexpected link type %d", t)
+	}
+	return &Reader{r: r, order: order}, nil
+}
+
+// ReadPacket reads the next packet record.
+// It returns io.EOF when there are no more packets.
+func (r *Reader) ReadPacket() (Packet, error) {
+	var rec [16]byte
+	_, err := io.ReadFull(r.r, rec[:])
+	if err != nil {
+		return Packet{}, err
+	}
+	sec := int64(r.order.Uint32(rec[0:]))
+	usec := int64(r.order.Uint32(rec[4:]))
+	n := r.order.Uint32(rec[8:])
+	if n < HeaderSize || n > snapLen {
+		return Packet{}, fmt.Errorf("invalid record length %d", n)
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r.r, buf)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return Packet{}, err
+	}
+	if buf[0] != headerVersion || buf[1] != HeaderSize {
+		return Packet{}, fmt.Errorf("unsupported radio header (version %d, length %d)", buf[0], buf[1])
+	}
+	return Packet{
+		Time:      time.Unix(sec, usec*1000),
+		RSSI:      int(int16(binary.BigEndian.Uint16(buf[2:]))),
+		Frequency: binary.BigEndian.Uint32(buf[4:]),
+		FEI:       int(int32(binary.BigEndian.Uint32(buf[8:]))),
+		Data:      buf[HeaderSize:],
+	}, nil
+}
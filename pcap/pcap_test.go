@@ -0,0 +1,65 @@
+package pcap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t0 := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	packets := []Packet{
+		{t0, 916600000, -45, 1221, []byte{0xA7, 0x12, 0x34, 0x56}},
+		{t0.Add(1500 * time.Microsecond), 868300000, -110, -61, []byte{}},
+		{t0.Add(time.Hour), 433920000, -80, 0, bytes.Repeat([]byte{0x55}, 110)},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packets {
+		err = w.WritePacket(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range packets {
+		p, err := r.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.Time.Equal(want.Time) || p.Frequency != want.Frequency || p.RSSI != want.RSSI || p.FEI != want.FEI || !bytes.Equal(p.Data, want.Data) {
+			t.Errorf("ReadPacket() == %+v, want %+v", p, want)
+		}
+	}
+	_, err = r.ReadPacket()
+	if err != io.EOF {
+		t.Errorf("ReadPacket() at end returned %v, want EOF", err)
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0xD4, 0xC3, 0xB2, 0xA1, 0x02, 0x00, 0x04, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0x00, 0x00, 0x93, 0x00, 0x00, 0x00,
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("file header == % X, want % X", buf.Bytes(), want)
+	}
+	_, err = NewReader(bytes.NewReader([]byte("not a capture file at all")))
+	if err == nil {
+		t.Errorf("NewReader should have rejected invalid header")
+	}
+}
@@ -0,0 +1,219 @@
+package rfm95
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ecc1/radio"
+)
+
+// ChannelPlan describes the channels and regulatory limits of a region.
+type ChannelPlan struct {
+	Name      string
+	Min       uint32  // lowest allowed frequency, in Hertz
+	Max       uint32  // highest allowed frequency, in Hertz
+	First     uint32  // center frequency of channel 0, in Hertz
+	Spacing   uint32  // channel spacing, in Hertz
+	Channels  int     // number of channels
+	MaxPower  int     // maximum output power, in dBm
+	DutyCycle float64 // maximum fraction of time spent transmitting
+}
+
+// Built-in channel plans, based on the LoRaWAN regional parameters.
+var (
+	US915 = ChannelPlan{
+		Name:      "US915",
+		Min:       902000000,
+		Max:       928000000,
+		First:     902300000,
+		Spacing:   200000,
+		Channels:  64,
+		MaxPower:  30,
+		DutyCycle: 1,
+	}
+	EU868 = ChannelPlan{
+		Name:      "EU868",
+		Min:       863000000,
+		Max:       870000000,
+		First:     863100000,
+		Spacing:   200000,
+		Channels:  35,
+		MaxPower:  14,
+		DutyCycle: 0.01,
+	}
+	AU915 = ChannelPlan{
+		Name:      "AU915",
+		Min:       915000000,
+		Max:       928000000,
+		First:     915200000,
+		Spacing:   200000,
+		Channels:  64,
+		MaxPower:  30,
+		DutyCycle: 1,
+	}
+	AS923 = ChannelPlan{
+		Name:      "AS923",
+		Min:       920000000,
+		Max:       925000000,
+		First:     920200000,
+		Spacing:   200000,
+		Channels:  24,
+		MaxPower:  16,
+		DutyCycle: 0.01,
+	}
+)
+
+// ChannelPlans lists the built-in channel plans.
+var ChannelPlans = []*ChannelPlan{&US915, &EU868, &AU915, &AS923}
+
+// LookupChannelPlan returns a copy of the built-in channel plan with the given name.
+func LookupChannelPlan(name string) (*ChannelPlan, error) {
+	for _, p := range ChannelPlans {
+		if strings.EqualFold(p.Name, name) {
+			c := *p
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown channel plan %q", name)
+}
+
+// FrequencyError indicates a frequency that is not allowed.
+type FrequencyError struct {
+	Frequency uint32
+	Band      string
+}
+
+func (e FrequencyError) Error() string {
+	return fmt.Sprintf("%s MHz is outside the %s band", radio.MegaHertz(e.Frequency), e.Band)
+}
+
+// ChannelError indicates a channel number that does not exist.
+type ChannelError struct {
+	Channel int
+	Plan    string
+}
+
+func (e ChannelError) Error() string {
+	return fmt.Sprintf("channel %d does not exist in the %s plan", e.Channel, e.Plan)
+}
+
+// Validate checks that the given frequency, in Hertz, is allowed by the plan.
+func (p *ChannelPlan) Validate(freq uint32) error {
+	if freq < p.Min || freq > p.Max {
+		return FrequencyError{Frequency: freq, Band: p.Name}
+	}
+	return nil
+}
+
+// Frequency returns the center frequency of the given channel, in Hertz.
+func (p *ChannelPlan) Frequency(ch int) (uint32, error) {
+	if ch < 0 || ch >= p.Channels {
+		return 0, ChannelError{Channel: ch, Plan: p.Name}
+	}
+	return p.First + uint32(ch)*p.Spacing, nil
+}
+
+// Channel returns the channel whose center frequency is the given frequency,
+// or false if there is no such channel.
+func (p *ChannelPlan) Channel(freq uint32) (int, bool) {
+	return p.nearestChannel(freq, 0)
+}
+
+// nearestChannel returns the channel whose center frequency
+// is within tolerance of the given frequency, or false if there is none.
+// The tolerance must be less than half the channel spacing.
+func (p *ChannelPlan) nearestChannel(freq uint32, tolerance uint32) (int, bool) {
+	if p.Spacing == 0 {
+		return 0, false
+	}
+	offset := int64(freq) - int64(p.First)
+	spacing := int64(p.Spacing)
+	ch := (offset + spacing/2) / spacing
+	if offset+spacing/2 < 0 || ch >= int64(p.Channels) {
+		return 0, false
+	}
+	diff := offset - ch*spacing
+	if diff < -int64(tolerance) || diff > int64(tolerance) {
+		return 0, false
+	}
+	return int(ch), true
+}
+
+// Frequencies returns the center frequencies of all channels in the plan.
+func (p *ChannelPlan) Frequencies() []uint32 {
+	return Channels(p.First, p.Spacing, p.Channels)
+}
+
+// SetChannelPlan sets the radio's active channel plan.
+// Subsequent frequency changes are validated against it.
+// A nil plan disables validation.
+// The radio keeps its own copy, so later changes to p have no effect.
+func (r *Radio) SetChannelPlan(p *ChannelPlan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p == nil {
+		r.plan = nil
+		return
+	}
+	c := *p
+	r.plan = &c
+}
+
+// ChannelPlan returns a copy of the radio's active channel plan, or nil if there is none.
+func (r *Radio) ChannelPlan() *ChannelPlan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plan == nil {
+		return nil
+	}
+	c := *r.plan
+	return &c
+}
+
+// validFrequency checks the given frequency against the radio's variant
+// and active channel plan, setting the radio's error state if it is not allowed.
+func (r *Radio) validFrequency(freq uint32) bool {
+	err := r.variant.Validate(freq)
+	if err == nil && r.plan != nil {
+		err = r.plan.Validate(freq)
+	}
+	if err != nil {
+		r.setError(err)
+		return false
+	}
+	return true
+}
+
+// SetChannel sets the radio to the given channel of the active plan.
+func (r *Radio) SetChannel(ch int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plan == nil {
+		r.setError(fmt.Errorf("no channel plan"))
+		return
+	}
+	f, err := r.plan.Frequency(ch)
+	if err != nil {
+		r.setError(err)
+		return
+	}
+	r.setFrequency(f)
+}
+
+// Channel returns the radio's current channel in the active plan,
+// or false if the current frequency is not a channel center.
+// Since the frequency is programmed in steps of FXOSC/2^19 (about 61 Hz),
+// a frequency within one step of a channel center is considered to be on it.
+func (r *Radio) Channel() (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plan == nil {
+		return 0, false
+	}
+	freq := r.frequency()
+	if r.error() != nil {
+		return 0, false
+	}
+	return r.plan.nearestChannel(freq, uint32(math.Ceil(fstep(r.xo()))))
+}
@@ -0,0 +1,129 @@
+package rfm95
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChannelPlan(t *testing.T) {
+	cases := []struct {
+		plan *ChannelPlan
+		ch   int
+		f    uint32
+	}{
+		{&US915, 0, 902300000},
+		{&US915, 63, 914900000},
+		{&EU868, 26, 868300000},
+		{&AU915, 0, 915200000},
+		{&AS923, 15, 923200000},
+	}
+	for _, c := range cases {
+		f, err := c.plan.Frequency(c.ch)
+		if err != nil {
+			t.Errorf("%s.Frequency(%d): %v", c.plan.Name, c.ch, err)
+			continue
+		}
+		if f != c.f {
+			t.Errorf("%s.Frequency(%d) == %d, want %d", c.plan.Name, c.ch, f, c.f)
+		}
+		ch, ok := c.plan.Channel(c.f)
+		if !ok || ch != c.ch {
+			t.Errorf("%s.Channel(%d) == %d, %v, want %d, true", c.plan.Name, c.f, ch, ok, c.ch)
+		}
+		if err := c.plan.Validate(f); err != nil {
+			t.Errorf("%s.Validate(%d): %v", c.plan.Name, f, err)
+		}
+	}
+	for _, p := range ChannelPlans {
+		for i, f := range p.Frequencies() {
+			if err := p.Validate(f); err != nil {
+				t.Errorf("%s channel %d: %v", p.Name, i, err)
+			}
+		}
+		if _, err := p.Frequency(p.Channels); err == nil {
+			t.Errorf("%s.Frequency(%d) succeeded, want error", p.Name, p.Channels)
+		}
+	}
+}
+
+func TestChannelPlanValidate(t *testing.T) {
+	cases := []struct {
+		plan *ChannelPlan
+		f    uint32
+		ok   bool
+	}{
+		{&US915, 916600000, true},
+		{&US915, 868350000, false},
+		{&EU868, 868350000, true},
+		{&EU868, 916600000, false},
+		{&AU915, 914000000, false},
+		{&AS923, 923200000, true},
+	}
+	for _, c := range cases {
+		err := c.plan.Validate(c.f)
+		if (err == nil) != c.ok {
+			t.Errorf("%s.Validate(%d) == %v, want ok == %v", c.plan.Name, c.f, err, c.ok)
+		}
+	}
+	if _, ok := US915.Channel(916600000); ok {
+		t.Errorf("US915.Channel(916600000) succeeded for off-grid frequency")
+	}
+}
+
+func TestLookupChannelPlan(t *testing.T) {
+	p, err := LookupChannelPlan("eu868")
+	if err != nil || *p != EU868 {
+		t.Errorf("LookupChannelPlan(eu868) == %v, %v", p, err)
+	}
+	p.MaxPower = 30
+	if EU868.MaxPower != 14 {
+		t.Errorf("changing the result of LookupChannelPlan changed EU868")
+	}
+	if _, err := LookupChannelPlan("XX123"); err == nil {
+		t.Errorf("LookupChannelPlan(XX123) succeeded, want error")
+	}
+}
+
+func TestInitChannelPlan(t *testing.T) {
+	r, hw := newFakeRadio()
+	r.SetChannelPlan(&EU868)
+	r.Init(915000000)
+	var ferr FrequencyError
+	if !errors.As(r.Error(), &ferr) {
+		t.Fatalf("Init(915000000) with EU868 plan: error %v, want FrequencyError", r.Error())
+	}
+	if hw.regs[RegFrfMsb] != 0 || hw.regs[RegOpMode] != 0 {
+		t.Errorf("Init(915000000) with EU868 plan changed the radio's registers")
+	}
+	p := r.ChannelPlan()
+	p.Max = 930000000
+	if r.ChannelPlan().Max != EU868.Max {
+		t.Errorf("changing the result of ChannelPlan changed the radio's plan")
+	}
+}
+
+func TestSetChannel(t *testing.T) {
+	for _, p := range ChannelPlans {
+		for _, ppm := range []float64{0, 23.5} {
+			r, _ := newFakeRadio()
+			r.ppm = ppm
+			r.SetChannelPlan(p)
+			for ch := 0; ch < p.Channels; ch++ {
+				r.SetChannel(ch)
+				got, ok := r.Channel()
+				if r.Error() != nil || !ok || got != ch {
+					t.Errorf("%s at %g ppm: SetChannel(%d) then Channel() == %d, %v (%d Hz, %v)", p.Name, ppm, ch, got, ok, r.Frequency(), r.Error())
+				}
+			}
+			// Halfway between channels is not on a channel.
+			f, _ := p.Frequency(0)
+			r.SetFrequency(f + p.Spacing/2)
+			if ch, ok := r.Channel(); ok {
+				t.Errorf("%s: Channel() == %d at %d Hz, want false", p.Name, ch, f+p.Spacing/2)
+			}
+		}
+	}
+	if _, ok := US915.Channel(902299987); ok {
+		t.Errorf("US915.Channel(902299987) succeeded, want exact match only")
+	}
+}
@@ -0,0 +1,129 @@
+package rfm95
+
+import (
+	"fmt"
+)
+
+// Maximum length of the sync word, in bytes.
+const maxSyncWordLength = 8
+
+// SyncWord returns the radio's sync word,
+// or nil if sync word generation and detection is disabled.
+func (r *Radio) SyncWord() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg := r.hw.ReadRegister(RegSyncConfig)
+	if cfg&SyncOn == 0 {
+		return nil
+	}
+	n := int(cfg&SyncSizeMask)>>SyncSizeShift + 1
+	return r.hw.ReadBurst(RegSyncValue1, n)
+}
+
+// SetSyncWord sets the radio's sync word, which must be 1 to 8 bytes long.
+// An empty sync word disables sync word generation and detection.
+// (The SX127x does not support bit errors in the sync word;
+// see SetPreambleDetector for the preamble detector's tolerance.)
+// Init and InitRF restore the default sync word (FF 00 FF 00),
+// so SetSyncWord must be called after them.
+func (r *Radio) SetSyncWord(word []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(word) > maxSyncWordLength {
+		r.setError(fmt.Errorf("sync word length %d is greater than %d", len(word), maxSyncWordLength))
+		return
+	}
+	cfg := r.hw.ReadRegister(RegSyncConfig)
+	if len(word) == 0 {
+		r.hw.WriteRegister(RegSyncConfig, cfg&^SyncOn)
+		return
+	}
+	r.hw.WriteBurst(RegSyncValue1, word)
+	cfg = cfg&^SyncSizeMask | SyncOn | byte(len(word)-1)<<SyncSizeShift
+	r.hw.WriteRegister(RegSyncConfig, cfg)
+}
+
+// PreambleLength returns the length of the transmitted preamble, in bytes.
+func (r *Radio) PreambleLength() uint16 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.hw.ReadBurst(RegPreambleMsb, 2)
+	if r.error() != nil {
+		return 0
+	}
+	return uint16(p[0])<<8 | uint16(p[1])
+}
+
+// SetPreambleLength sets the length of the transmitted preamble, in bytes.
+func (r *Radio) SetPreambleLength(n uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hw.WriteBurst(RegPreambleMsb, []byte{byte(n >> 8), byte(n)})
+}
+
+// PreamblePolarity returns the radio's preamble polarity
+// (PreamblePolarityAA or PreamblePolarity55).
+func (r *Radio) PreamblePolarity() byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hw.ReadRegister(RegSyncConfig) & PreamblePolarityMask
+}
+
+// SetPreamblePolarity sets the radio's preamble polarity
+// (PreamblePolarityAA or PreamblePolarity55).
+func (r *Radio) SetPreamblePolarity(polarity byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if polarity&^PreamblePolarityMask != 0 {
+		r.setError(fmt.Errorf("invalid preamble polarity %02X", polarity))
+		return
+	}
+	cfg := r.hw.ReadRegister(RegSyncConfig)
+	r.hw.WriteRegister(RegSyncConfig, cfg&^PreamblePolarityMask|polarity)
+}
+
+// PreambleDetector returns the number of preamble bytes the receiver
+// must detect and the number of chip errors it tolerates.
+// A size of 0 means the preamble detector is disabled.
+func (r *Radio) PreambleDetector() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return registerToPreambleDetect(r.hw.ReadRegister(RegPreambleDetect))
+}
+
+// SetPreambleDetector sets the number of preamble bytes (1 to 3) the receiver
+// must detect and the number of chip errors (0 to 31) it tolerates.
+// A size of 0 disables the preamble detector.
+func (r *Radio) SetPreambleDetector(size int, tolerance int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, err := preambleDetectToRegister(size, tolerance)
+	if err != nil {
+		r.setError(err)
+		return
+	}
+	r.hw.WriteRegister(RegPreambleDetect, v)
+}
+
+// See data sheet section 4.2.11.2.
+// PreambleDetectorSize encodes 1 to 3 bytes as 0 to 2.
+func preambleDetectToRegister(size int, tolerance int) (byte, error) {
+	if size < 0 || size > 3 {
+		return 0, fmt.Errorf("preamble detector size %d is not between 0 and 3", size)
+	}
+	if tolerance < 0 || tolerance > PreambleDetectorTolMask {
+		return 0, fmt.Errorf("preamble detector tolerance %d is not between 0 and %d", tolerance, PreambleDetectorTolMask)
+	}
+	if size == 0 {
+		return byte(tolerance) << PreambleDetectorTolShift, nil
+	}
+	return PreambleDetectorOn | byte(size-1)<<PreambleDetectorSizeShift | byte(tolerance)<<PreambleDetectorTolShift, nil
+}
+
+func registerToPreambleDetect(v byte) (int, int) {
+	tol := int(v&PreambleDetectorTolMask) >> PreambleDetectorTolShift
+	if v&PreambleDetectorOn == 0 {
+		return 0, tol
+	}
+	return int(v&PreambleDetectorSizeMask)>>PreambleDetectorSizeShift + 1, tol
+}
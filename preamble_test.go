@@ -0,0 +1,74 @@
+package rfm95
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPreambleDetect(t *testing.T) {
+	cases := []struct {
+		size int
+		tol  int
+		r    byte
+	}{
+		{0, 0, 0x00},
+		{1, 0, 0x80},
+		{2, 10, 0xAA},
+		{3, 31, 0xDF},
+	}
+	for _, c := range cases {
+		r, err := preambleDetectToRegister(c.size, c.tol)
+		if err != nil {
+			t.Errorf("preambleDetectToRegister(%d, %d): %v", c.size, c.tol, err)
+			continue
+		}
+		if r != c.r {
+			t.Errorf("preambleDetectToRegister(%d, %d) == %02X, want %02X", c.size, c.tol, r, c.r)
+		}
+		size, tol := registerToPreambleDetect(c.r)
+		if size != c.size || tol != c.tol {
+			t.Errorf("registerToPreambleDetect(%02X) == %d, %d, want %d, %d", c.r, size, tol, c.size, c.tol)
+		}
+	}
+	for _, c := range [][2]int{{4, 0}, {-1, 0}, {1, 32}} {
+		if _, err := preambleDetectToRegister(c[0], c[1]); err == nil {
+			t.Errorf("preambleDetectToRegister(%d, %d) succeeded, want error", c[0], c[1])
+		}
+	}
+}
+
+func TestSyncWord(t *testing.T) {
+	cases := []struct {
+		word []byte
+		ok   bool
+	}{
+		{[]byte{0x2D}, true},
+		{[]byte{0xFF, 0x00, 0xFF, 0x00}, true},
+		{[]byte{1, 2, 3, 4, 5, 6, 7, 8}, true},
+		{nil, true},
+		{[]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, false},
+	}
+	for _, c := range cases {
+		r, _ := newFakeRadio()
+		r.SetSyncWord(c.word)
+		if (r.Error() == nil) != c.ok {
+			t.Errorf("SetSyncWord(% X) error == %v, want ok == %v", c.word, r.Error(), c.ok)
+			continue
+		}
+		if !c.ok {
+			continue
+		}
+		w := r.SyncWord()
+		if !bytes.Equal(w, c.word) {
+			t.Errorf("SyncWord() == % X after SetSyncWord(% X)", w, c.word)
+		}
+	}
+	// Disabling the sync word preserves the other fields of RegSyncConfig.
+	r, hw := newFakeRadio()
+	hw.regs[RegSyncConfig] = PreamblePolarity55
+	r.SetSyncWord([]byte{0x12, 0x34})
+	r.SetSyncWord(nil)
+	if hw.regs[RegSyncConfig]&^SyncSizeMask != PreamblePolarity55 {
+		t.Errorf("RegSyncConfig == %02X after disabling the sync word", hw.regs[RegSyncConfig])
+	}
+}
@@ -0,0 +1,256 @@
+package rfm95
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a register configuration that can be saved as JSON or YAML.
+// Registers and their fields are identified by the names used in
+// FSKRegisters and LoRaRegisters. Only read-write fields are included;
+// registers and fields that are not mentioned keep their reset values.
+type Profile struct {
+	Name        string                     `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string                     `json:"description,omitempty" yaml:"description,omitempty"`
+	Registers   map[string]map[string]byte `json:"registers" yaml:"registers"`
+}
+
+// ValidateConfiguration checks that a configuration has the expected length
+// and that its reserved bits have their reset values.
+func ValidateConfiguration(config []byte) error {
+	n := len(resetConfiguration)
+	if len(config) != n {
+		return fmt.Errorf("configuration length = %d, expected %d", len(config), n)
+	}
+	values, err := DecodeConfiguration(config)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		reg := v.Register
+		m := reg.ReservedMask()
+		if v.Value&m != reg.Reset&m {
+			return fmt.Errorf("%s = %02X: reserved bits %08b should be %08b", reg.Name, v.Value, v.Value&m, reg.Reset&m)
+		}
+	}
+	return nil
+}
+
+// NewProfile creates a profile from a configuration snapshot,
+// such as the result of ReadConfiguration.
+func NewProfile(name string, config []byte) (*Profile, error) {
+	err := ValidateConfiguration(config)
+	if err != nil {
+		return nil, err
+	}
+	values, err := DecodeConfiguration(config)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{Name: name, Registers: make(map[string]map[string]byte)}
+	for _, v := range values {
+		fields := make(map[string]byte)
+		for _, f := range v.Fields {
+			if f.Field.Access == ReadWrite {
+				fields[f.Field.Name] = f.Value
+			}
+		}
+		if len(fields) != 0 {
+			p.Registers[v.Register.Name] = fields
+		}
+	}
+	return p, nil
+}
+
+// Configuration returns the register configuration described by the profile.
+func (p *Profile) Configuration() ([]byte, error) {
+	config := ResetConfiguration()
+	regs := FSKRegisters
+	if p.Registers["RegOpMode"]["LongRangeMode"] != 0 {
+		regs = LoRaRegisters
+	}
+	// Process registers and fields in a fixed order so errors are reproducible.
+	for _, name := range sortedKeys(p.Registers) {
+		reg := lookupRegisterName(regs, name)
+		if reg == nil || int(reg.Address) < ConfigurationStart || int(reg.Address) >= len(config) {
+			return nil, fmt.Errorf("%s: unknown register", name)
+		}
+		v := config[reg.Address]
+		fields := p.Registers[name]
+		for _, fname := range sortedKeys(fields) {
+			value := fields[fname]
+			f := reg.Field(fname)
+			if f == nil {
+				return nil, fmt.Errorf("%s: unknown field %s", name, fname)
+			}
+			if f.Access != ReadWrite {
+				return nil, fmt.Errorf("%s: field %s is not writable", name, fname)
+			}
+			if value > f.Mask()>>f.Shift {
+				return nil, fmt.Errorf("%s: value %d does not fit in %d-bit field %s", name, value, f.Width, fname)
+			}
+			v = v&^f.Mask() | value<<f.Shift
+		}
+		config[reg.Address] = v
+	}
+	return config, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func lookupRegisterName(regs []Register, name string) *Register {
+	for i := range regs {
+		if regs[i].Name == name {
+			return &regs[i]
+		}
+	}
+	return nil
+}
+
+// ReadProfile reads a JSON-encoded profile and validates it.
+func ReadProfile(r io.Reader) (*Profile, error) {
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	p := &Profile{}
+	err := d.Decode(p)
+	if err != nil {
+		return nil, err
+	}
+	return p.validate()
+}
+
+// ReadProfileYAML reads a YAML-encoded profile and validates it.
+func ReadProfileYAML(r io.Reader) (*Profile, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	p := &Profile{}
+	err := d.Decode(p)
+	if err != nil {
+		return nil, err
+	}
+	return p.validate()
+}
+
+func (p *Profile) validate() (*Profile, error) {
+	_, err := p.Configuration()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Write writes the profile in JSON format.
+func (p *Profile) Write(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(p)
+}
+
+// WriteYAML writes the profile in YAML format.
+func (p *Profile) WriteYAML(w io.Writer) error {
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	err := e.Encode(p)
+	if err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// isYAML reports whether a profile file name has a YAML extension.
+func isYAML(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadProfile reads a profile from the given file,
+// in YAML format if its extension is .yaml or .yml and JSON otherwise.
+func LoadProfile(file string) (*Profile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if isYAML(file) {
+		return ReadProfileYAML(f)
+	}
+	return ReadProfile(f)
+}
+
+// Save writes the profile to the given file,
+// in YAML format if its extension is .yaml or .yml and JSON otherwise.
+func (p *Profile) Save(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if isYAML(file) {
+		err = p.WriteYAML(f)
+	} else {
+		err = p.Write(f)
+	}
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Profile returns the radio's current configuration as a profile.
+func (r *Radio) Profile(name string) *Profile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	config := r.readConfiguration(true)
+	if r.error() != nil {
+		return nil
+	}
+	p, err := NewProfile(name, config)
+	if err != nil {
+		r.setError(err)
+		return nil
+	}
+	return p
+}
+
+// ApplyProfile writes the configuration described by the given profile to the radio.
+// Profiles describe the SX1276 register map, so they cannot be applied to an SX1272.
+func (r *Radio) ApplyProfile(p *Profile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return
+	}
+	if r.chip() != sx1276 {
+		r.setError(fmt.Errorf("profiles are not supported on the %s", r.chip().name))
+		return
+	}
+	config, err := p.Configuration()
+	if err != nil {
+		r.setError(err)
+		return
+	}
+	// Mode changes require sleep mode, so apply the rest of the configuration first.
+	mode := config[RegOpMode]
+	r.setMode(SleepMode)
+	config[RegOpMode] = mode&^ModeMask | SleepMode
+	r.writeConfiguration(config, true)
+	r.setMode(mode & ModeMask)
+}
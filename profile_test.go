@@ -0,0 +1,148 @@
+package rfm95
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	config := DefaultConfiguration()
+	config[RegOpMode] = FskOokMode | ModulationTypeOOK | SleepMode
+	config[RegSyncValue1] = 0xFF
+	config[RegPacketConfig1] = FixedLength
+	p, err := NewProfile("test", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = p.Write(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"SyncValue(63:56)": 255`) {
+		t.Errorf("JSON profile does not contain sync word:\n%s", buf.String())
+	}
+	q, err := ReadProfile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Name != "test" {
+		t.Errorf("profile name == %q, want %q", q.Name, "test")
+	}
+	c, err := q.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c, config) {
+		t.Errorf("round-trip configuration\n% X\nwant\n% X", c, config)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	cases := []struct {
+		json string
+		err  string
+	}{
+		{`{"registers": {"RegBogus": {"X": 1}}}`, "unknown register"},
+		{`{"registers": {"RegRxBw": {"RxBwFoo": 1}}}`, "unknown field"},
+		{`{"registers": {"RegRxBw": {"RxBwMant": 4}}}`, "does not fit"},
+		{`{"registers": {"RegVersion": {"Version": 3}}}`, "not writable"},
+		{`{"registers": {}, "extra": 1}`, "unknown field"},
+	}
+	for _, c := range cases {
+		_, err := ReadProfile(strings.NewReader(c.json))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("ReadProfile(%s) returned %v, want %q error", c.json, err, c.err)
+		}
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	if err := ValidateConfiguration(DefaultConfiguration()); err != nil {
+		t.Errorf("DefaultConfiguration: %v", err)
+	}
+	if err := ValidateConfiguration(ResetConfiguration()[:0x40]); err == nil {
+		t.Errorf("short configuration was accepted")
+	}
+	config := ResetConfiguration()
+	config[RegLna] |= 1 << 2
+	if err := ValidateConfiguration(config); err == nil {
+		t.Errorf("configuration with reserved RegLna bit set was accepted")
+	}
+	config = ResetConfiguration()
+	config[0x18] = 0
+	if err := ValidateConfiguration(config); err == nil {
+		t.Errorf("configuration with modified reserved register was accepted")
+	}
+}
+
+func TestProfileYAML(t *testing.T) {
+	config := DefaultConfiguration()
+	config[RegSyncValue1] = 0xFF
+	p, err := NewProfile("test", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = p.WriteYAML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "SyncValue(63:56): 255") {
+		t.Errorf("YAML profile does not contain sync word:\n%s", buf.String())
+	}
+	q, err := ReadProfileYAML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := q.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Name != "test" || !bytes.Equal(c, config) {
+		t.Errorf("YAML round trip == %q, % X, want %q, % X", q.Name, c, "test", config)
+	}
+	_, err = ReadProfileYAML(strings.NewReader("registers: {}\nextra: 1\n"))
+	if err == nil {
+		t.Errorf("ReadProfileYAML accepted an unknown field")
+	}
+	// Files are saved and loaded in the format given by their extension.
+	dir := t.TempDir()
+	for _, name := range []string{"p.json", "p.yaml", "p.yml"} {
+		file := filepath.Join(dir, name)
+		err = p.Save(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if json := data[0] == '{'; json != (name == "p.json") {
+			t.Errorf("%s saved as:\n%s", name, data)
+		}
+		q, err = LoadProfile(file)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c, _ := q.Configuration(); !bytes.Equal(c, config) {
+			t.Errorf("%s: loaded configuration differs", name)
+		}
+	}
+}
+
+// The first invalid field in name order is reported, regardless of map order.
+func TestProfileFieldOrder(t *testing.T) {
+	p := &Profile{Registers: map[string]map[string]byte{
+		"RegRxBw": {"RxBwMant": 4, "RxBwExp": 9, "Zzz": 1, "Aaa": 1},
+	}}
+	for i := 0; i < 20; i++ {
+		_, err := p.Configuration()
+		if err == nil || !strings.Contains(err.Error(), "unknown field Aaa") {
+			t.Fatalf("Configuration() returned %v, want unknown field Aaa", err)
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package rfm95
 
 import (
+	"bytes"
+	"context"
 	"log"
+	"log/slog"
 	"time"
 )
 
 const (
-	debug         = false
 	maxPacketSize = 110
 	fifoSize      = 64
 
@@ -16,50 +18,62 @@ const (
 
 	// Approximate time for one byte to be transmitted, based on the data rate.
 	byteDuration = time.Millisecond
-)
 
-func init() {
-	if debug {
-		log.SetFlags(log.Ltime | log.Lmicroseconds | log.LUTC)
-	}
-}
+	// Maximum time to wait for a receive interrupt without releasing the radio
+	// to other goroutines.
+	interruptSlice = 20 * time.Millisecond
+)
 
 // Send transmits the given packet.
 func (r *Radio) Send(data []byte) {
-	if r.Error() != nil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.send(data)
+}
+
+func (r *Radio) send(data []byte) {
+	if r.error() != nil {
 		return
 	}
 	if len(data) > maxPacketSize {
 		log.Panicf("attempting to send %d-byte packet", len(data))
 	}
-	if debug {
-		log.Printf("sending %d-byte packet in %s state", len(data), r.State())
+	if r.debugging() {
+		r.logger.Debug("sending packet", "size", len(data), "state", r.state())
 	}
+	size := len(data)
+	data = encode(r.encoding, data)
 	// Terminate packet with zero byte.
 	copy(r.txPacket, data)
 	r.txPacket[len(data)] = 0
 	packet := r.txPacket[:len(data)+1]
 	r.clearFIFO()
 	r.setMode(StandbyMode)
+	restore := r.unlimitedLength()
+	if restore == nil {
+		return
+	}
+	defer restore()
 	r.hw.WriteRegister(RegFifoThresh, TxStartCondition|fifoThreshold<<FifoThresholdShift)
 	// Use the sequencer to transmit the packet automatically.
 	r.hw.WriteRegister(RegSeqConfig1, SequencerStart|IdleModeStandby|FromStartToTX)
 	r.transmit(packet)
 	r.setMode(StandbyMode)
+	if r.error() == nil {
+		r.stats.PacketsSent++
+		r.stats.BytesSent += uint64(size)
+	}
 }
 
 func (r *Radio) transmit(data []byte) {
 	avail := fifoSize
-	for r.Error() == nil {
+	for r.error() == nil {
 		if avail > len(data) {
 			avail = len(data)
 		}
-		if debug {
-			log.Printf("writing %d bytes to TX FIFO\n", avail)
-		}
 		r.hw.WriteBurst(RegFifo, data[:avail])
-		if debug {
-			log.Printf("in %s state after FIFO write", r.State())
+		if r.debugging() {
+			r.logger.Debug("wrote TX FIFO", "bytes", avail, "state", r.state())
 		}
 		data = data[avail:]
 		if len(data) == 0 {
@@ -68,7 +82,7 @@ func (r *Radio) transmit(data []byte) {
 		// Wait until there is room for at least fifoSize - fifoThreshold bytes in the FIFO.
 		// Err on the short side here to avoid TXFIFO underflow.
 		time.Sleep(fifoSize / 4 * byteDuration)
-		for r.Error() == nil {
+		for r.error() == nil {
 			if !r.fifoThresholdExceeded() {
 				avail = fifoSize - fifoThreshold
 				break
@@ -80,17 +94,17 @@ func (r *Radio) transmit(data []byte) {
 
 func (r *Radio) finishTX(numBytes int) {
 	// Wait for automatic return to standby mode when FIFO is empty.
-	for r.Error() == nil {
+	for r.error() == nil {
 		s := r.mode()
 		if s == StandbyMode {
-			if debug {
-				log.Printf("transmit completed")
-			}
+			r.logger.Debug("transmit completed")
 			break
 		}
-		if debug || s != TransmitterMode {
-			log.Printf("waiting for TX to finish in %s state", stateName(s))
+		level := slog.LevelDebug
+		if s != TransmitterMode {
+			level = slog.LevelWarn
 		}
+		r.logger.Log(context.Background(), level, "waiting for transmit to finish", "state", stateName(s))
 		time.Sleep(byteDuration)
 	}
 }
@@ -108,27 +122,105 @@ func (r *Radio) fifoThresholdExceeded() bool {
 }
 
 func (r *Radio) clearFIFO() {
+	if r.hw.ReadRegister(RegIrqFlags2)&FifoOverrun != 0 {
+		r.stats.FIFOOverruns++
+	}
 	r.hw.WriteRegister(RegIrqFlags2, FifoOverrun)
 }
 
 // Receive listens with the given timeout for an incoming packet.
 // It returns the packet and the associated RSSI.
+// When address filtering is enabled, packets addressed to other nodes
+// are discarded, and the address byte is returned as the first byte of the packet.
+//
+// While Receive is waiting for a packet to begin, other goroutines may use the radio.
+// A Send from another goroutine interrupts the wait; Receive resumes listening
+// when the transmission is complete, without extending the timeout.
+// Configuration changes made by other goroutines during the wait
+// are kept when Receive returns.
+// Concurrent calls to Receive are serialized.
 func (r *Radio) Receive(timeout time.Duration) ([]byte, int) {
-	if r.Error() != nil {
+	r.rxMu.Lock()
+	defer r.rxMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.receiveAddressed(timeout)
+}
+
+func (r *Radio) receiveAddressed(timeout time.Duration) ([]byte, int) {
+	deadline := r.now().Add(timeout)
+	for {
+		p, rssi := r.receive(timeout)
+		if len(p) == 0 || r.acceptAddress(p[0]) {
+			return p, rssi
+		}
+		r.logger.Debug("discarding packet", "address", p[0])
+		timeout = deadline.Sub(r.now())
+		if timeout <= 0 {
+			return nil, rssi
+		}
+	}
+}
+
+// unlimitedLength switches the packet engine to the unlimited length
+// packet format (data sheet section 4.2.13.2), in which Send and Receive
+// frame packets with a zero byte, keeping its other settings.
+// It returns a function that restores the previous format,
+// or nil if the configuration could not be read.
+//
+// Receive releases the radio while it waits, so another goroutine may
+// change the configuration in the meantime. Only the bits that still
+// have the values written here are restored, so such changes are kept.
+func (r *Radio) unlimitedLength() func() {
+	saved := r.hw.ReadBurst(RegPacketConfig1, 3)
+	if r.error() != nil {
+		return nil
+	}
+	cfg := []byte{saved[0] &^ VariableLength, saved[1]&^PayloadLengthMSBMask | PacketMode, 0}
+	if r.encoding != EncodingNone {
+		// The packet engine sees encoded data, so address filtering
+		// is done in software while an encoding is in use.
+		cfg[0] &^= AddressFilteringMask
+	}
+	if bytes.Equal(cfg, saved) {
+		return func() {}
+	}
+	r.hw.WriteBurst(RegPacketConfig1, cfg)
+	return func() {
+		cur := r.hw.ReadBurst(RegPacketConfig1, 3)
+		if r.error() != nil {
+			return
+		}
+		restored := make([]byte, len(cur))
+		for i := range cur {
+			// Bits changed above that nobody else has changed since.
+			m := (saved[i] ^ cfg[i]) &^ (cur[i] ^ cfg[i])
+			restored[i] = cur[i]&^m | saved[i]&m
+		}
+		if !bytes.Equal(restored, cur) {
+			r.hw.WriteBurst(RegPacketConfig1, restored)
+		}
+	}
+}
+
+func (r *Radio) receive(timeout time.Duration) ([]byte, int) {
+	if r.error() != nil {
+		return nil, 0
+	}
+	restore := r.unlimitedLength()
+	if restore == nil {
 		return nil, 0
 	}
-	// Use unlimited length packet format (data sheet section 4.2.13.2).
-	r.hw.WriteRegister(RegPacketConfig1, FixedLength)
-	r.hw.WriteRegister(RegPayloadLength, 0)
-	r.hw.WriteRegister(RegPacketConfig2, PacketMode|0)
+	defer restore()
 	r.setMode(ReceiverMode)
 	defer r.setMode(SleepMode)
-	if debug {
-		log.Printf("waiting for interrupt in %s state", r.State())
+	if r.debugging() {
+		r.logger.Debug("waiting for interrupt", "state", r.state())
 	}
-	r.hw.AwaitInterrupt(timeout)
-	rssi := r.ReadRSSI()
-	for r.Error() == nil {
+	r.awaitInterrupt(timeout)
+	rssi := r.readRSSI()
+	r.fei = r.readFEI()
+	for r.error() == nil {
 		if r.fifoEmpty() {
 			if timeout <= 0 {
 				break
@@ -138,7 +230,7 @@ func (r *Radio) Receive(timeout time.Duration) ([]byte, int) {
 			continue
 		}
 		c := r.hw.ReadRegister(RegFifo)
-		if r.Error() != nil {
+		if r.error() != nil {
 			break
 		}
 		if c == 0 {
@@ -150,6 +242,43 @@ func (r *Radio) Receive(timeout time.Duration) ([]byte, int) {
 	return nil, rssi
 }
 
+// awaitInterrupt waits with the given timeout for a receive interrupt.
+// The radio is unlocked while waiting, and receive mode is restored
+// if another goroutine has changed it.
+func (r *Radio) awaitInterrupt(timeout time.Duration) {
+	deadline := r.now().Add(timeout)
+	for r.error() == nil {
+		slice := deadline.Sub(r.now())
+		if slice > interruptSlice {
+			slice = interruptSlice
+		} else if slice < 0 {
+			slice = 0
+		}
+		hw := r.hw
+		r.mu.Unlock()
+		err := hw.WaitInterrupt(slice)
+		r.mu.Lock()
+		if r.mode() != ReceiverMode {
+			if r.debugging() {
+				r.logger.Debug("resuming receive", "state", r.state())
+			}
+			r.setMode(ReceiverMode)
+			continue
+		}
+		if err == nil {
+			break
+		}
+		if !isTimeout(err) {
+			r.setError(err)
+			break
+		}
+		if !r.now().Before(deadline) {
+			r.stats.Timeouts++
+			r.setError(err)
+		}
+	}
+}
+
 func (r *Radio) finishRX(rssi int) ([]byte, int) {
 	r.setMode(StandbyMode)
 	r.clearFIFO()
@@ -159,18 +288,31 @@ func (r *Radio) finishRX(rssi int) ([]byte, int) {
 	}
 	p := make([]byte, size)
 	_, r.err = r.receiveBuffer.Read(p)
-	if r.Error() != nil {
+	if r.error() != nil {
 		return nil, rssi
 	}
 	r.receiveBuffer.Reset()
 	// Remove spurious final byte consisting of just one or two high bits.
 	b := p[len(p)-1]
 	if b == 0x80 || b == 0xC0 {
-		log.Printf("end-of-packet glitch %X with RSSI %d", b, rssi)
+		r.logger.Warn("end-of-packet glitch", "byte", b, "rssi", rssi)
+		r.stats.GlitchTrims++
 		p = p[:len(p)-1]
 	}
-	if debug {
-		log.Printf("received %d-byte packet in %s state", size, r.State())
+	p, err := decode(r.encoding, p)
+	if err != nil {
+		r.logger.Warn("discarding packet", "size", size, "err", err)
+		r.stats.DecodeErrors++
+		return nil, rssi
+	}
+	if len(p) == 0 {
+		return nil, rssi
+	}
+	r.stats.PacketsReceived++
+	r.stats.BytesReceived += uint64(len(p))
+	r.stats.RSSI.observe(float64(rssi))
+	if r.debugging() {
+		r.logger.Debug("received packet", "size", size, "rssi", rssi, "state", r.state())
 	}
 	return p, rssi
 }
@@ -181,9 +323,13 @@ func (r *Radio) finishRX(rssi int) ([]byte, int) {
 // (This could be further optimized by using an Automode to go directly
 // from TX to RX, rather than returning to standby in between.)
 func (r *Radio) SendAndReceive(data []byte, timeout time.Duration) ([]byte, int) {
-	r.Send(data)
-	if r.Error() != nil {
+	r.rxMu.Lock()
+	defer r.rxMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.send(data)
+	if r.error() != nil {
 		return nil, 0
 	}
-	return r.Receive(timeout)
+	return r.receiveAddressed(timeout)
 }
@@ -0,0 +1,149 @@
+package rfm95
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReceive(t *testing.T) {
+	cases := []struct {
+		frame     []byte
+		filtering byte
+		packet    []byte
+	}{
+		{[]byte{0x12, 0x34, 0x00}, AddressFilteringNone, []byte{0x12, 0x34}},
+		{[]byte{0x12, 0x34, 0xC0, 0x00}, AddressFilteringNone, []byte{0x12, 0x34}},
+		{[]byte{0x12, 0x34, 0x00}, AddressFilteringNode, nil},
+		// A frame consisting only of an end-of-packet glitch is empty.
+		{[]byte{0x80, 0x00}, AddressFilteringNone, nil},
+		{[]byte{0x80, 0x00}, AddressFilteringNode, nil},
+	}
+	for _, c := range cases {
+		r, hw := newFakeRadio()
+		hw.regs[RegNodeAdrs] = 0x99
+		hw.regs[RegPacketConfig1] = c.filtering
+		hw.rx = append([]byte(nil), c.frame...)
+		p, _ := r.Receive(10 * time.Millisecond)
+		if !bytes.Equal(p, c.packet) || (p == nil) != (c.packet == nil) {
+			t.Errorf("Receive of % X with filtering %02X == % X, want % X", c.frame, c.filtering, p, c.packet)
+		}
+	}
+}
+
+func TestReceivePreservesPacketConfig(t *testing.T) {
+	r, hw := newFakeRadio()
+	saved := []byte{VariableLength | 2<<DcFreeShift | CrcOn | AddressFilteringNode, PacketMode | 1, 0x40}
+	copy(hw.regs[RegPacketConfig1:], saved)
+	hw.regs[RegNodeAdrs] = 0x12
+	hw.rx = []byte{0x12, 0x34, 0x00}
+	p, _ := r.Receive(10 * time.Millisecond)
+	if !bytes.Equal(p, []byte{0x12, 0x34}) {
+		t.Errorf("Receive == % X, want 12 34", p)
+	}
+	cfg := hw.regs[RegPacketConfig1 : RegPacketConfig1+3]
+	if !bytes.Equal(cfg, saved) {
+		t.Errorf("packet configuration after Receive == % X, want % X", cfg, saved)
+	}
+}
+
+// Run with -race to check the locking of a Send that interrupts a pending Receive.
+func TestSendDuringReceive(t *testing.T) {
+	r, hw := newFakeRadio()
+	done := make(chan []byte)
+	go func() {
+		p, _ := r.Receive(200 * time.Millisecond)
+		done <- p
+	}()
+	for {
+		hw.mu.Lock()
+		n := hw.rxStarts
+		hw.mu.Unlock()
+		if n != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.Send([]byte{1, 2, 3})
+	if r.Error() != nil {
+		t.Fatalf("Send during Receive: %v", r.Error())
+	}
+	if p := <-done; p != nil {
+		t.Errorf("Receive == % X, want nil", p)
+	}
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	if want := []byte{1, 2, 3, 0}; !bytes.Equal(hw.tx, want) {
+		t.Errorf("transmitted % X, want % X", hw.tx, want)
+	}
+	if hw.rxStarts < 2 {
+		t.Errorf("receiver mode entered %d times, want Receive to resume after Send", hw.rxStarts)
+	}
+	if s := r.Stats(); s.PacketsSent != 1 {
+		t.Errorf("PacketsSent == %d, want 1", s.PacketsSent)
+	}
+}
+
+func TestSendVariableLengthConfig(t *testing.T) {
+	r, hw := newFakeRadio()
+	saved := []byte{VariableLength | CrcOn, PacketMode, 0x40}
+	copy(hw.regs[RegPacketConfig1:], saved)
+	r.Send([]byte{0x05, 0x12, 0x34})
+	if r.Error() != nil {
+		t.Fatal(r.Error())
+	}
+	// The zero-terminated frame must be sent in unlimited length format.
+	if want := []byte{CrcOn, PacketMode, 0}; !bytes.Equal(hw.txConfig, want) {
+		t.Errorf("packet configuration during Send == % X, want % X", hw.txConfig, want)
+	}
+	cfg := hw.regs[RegPacketConfig1 : RegPacketConfig1+3]
+	if !bytes.Equal(cfg, saved) {
+		t.Errorf("packet configuration after Send == % X, want % X", cfg, saved)
+	}
+	hw.rx = hw.tx
+	p, _ := r.Receive(10 * time.Millisecond)
+	if !bytes.Equal(p, []byte{0x05, 0x12, 0x34}) {
+		t.Errorf("Receive of sent frame == % X, want 05 12 34", p)
+	}
+}
+
+// Configuration changes made while Receive is waiting must survive it.
+func TestConfigureDuringReceive(t *testing.T) {
+	r, hw := newFakeRadio()
+	saved := []byte{VariableLength | CrcOn, PacketMode, 0x40}
+	copy(hw.regs[RegPacketConfig1:], saved)
+	r.SetEncoding(EncodingManchester)
+	done := make(chan struct{})
+	go func() {
+		r.Receive(100 * time.Millisecond)
+		close(done)
+	}()
+	for {
+		hw.mu.Lock()
+		n := hw.rxStarts
+		hw.mu.Unlock()
+		if n != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.SetNodeAddress(0x42)
+	r.SetAddressFiltering(AddressFilteringNode)
+	<-done
+	if r.Error() != nil {
+		t.Fatal(r.Error())
+	}
+	if a := r.NodeAddress(); a != 0x42 {
+		t.Errorf("NodeAddress() == %02X after Receive, want 42", a)
+	}
+	if m := r.AddressFiltering(); m != AddressFilteringNode {
+		t.Errorf("AddressFiltering() == %02X after Receive, want %02X", m, AddressFilteringNode)
+	}
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	want := []byte{saved[0] | AddressFilteringNode, saved[1], saved[2]}
+	cfg := hw.regs[RegPacketConfig1 : RegPacketConfig1+3]
+	if !bytes.Equal(cfg, want) {
+		t.Errorf("packet configuration after Receive == % X, want % X", cfg, want)
+	}
+}
@@ -0,0 +1,690 @@
+package rfm95
+
+import (
+	"fmt"
+	"io"
+)
+
+// Access describes how a register field may be accessed.
+type Access int
+
+// Access types, as listed in the data sheet register tables.
+const (
+	ReadWrite    Access = iota // rw
+	ReadOnly                   // r
+	WriteTrigger               // w: writing 1 starts an action
+	ReadClear                  // rwc: writing 1 clears the flag
+)
+
+func (a Access) String() string {
+	switch a {
+	case ReadWrite:
+		return "rw"
+	case ReadOnly:
+		return "r"
+	case WriteTrigger:
+		return "w"
+	case ReadClear:
+		return "rwc"
+	default:
+		return fmt.Sprintf("Unknown Access (%d)", int(a))
+	}
+}
+
+// Field describes a bit field within a register.
+type Field struct {
+	Name        string
+	Shift       uint // position of the least significant bit
+	Width       uint // number of bits
+	Access      Access
+	Description string
+}
+
+// Mask returns the bits of the register occupied by the field.
+func (f Field) Mask() byte {
+	return byte((1<<f.Width)-1) << f.Shift
+}
+
+// Value extracts the field from the given register value.
+func (f Field) Value(v byte) byte {
+	return (v & f.Mask()) >> f.Shift
+}
+
+// Register describes a radio register.
+// Bits not covered by any field are reserved.
+type Register struct {
+	Name        string
+	Address     byte
+	Reset       byte
+	Description string
+	Fields      []Field
+}
+
+// ReservedMask returns the bits of the register not covered by any field.
+func (r *Register) ReservedMask() byte {
+	m := byte(0xFF)
+	for _, f := range r.Fields {
+		m &^= f.Mask()
+	}
+	return m
+}
+
+// Field returns the field with the given name, or nil.
+func (r *Register) Field(name string) *Field {
+	for i := range r.Fields {
+		if r.Fields[i].Name == name {
+			return &r.Fields[i]
+		}
+	}
+	return nil
+}
+
+func rw(name string, shift uint, width uint, desc string) Field {
+	return Field{Name: name, Shift: shift, Width: width, Access: ReadWrite, Description: desc}
+}
+
+func ro(name string, shift uint, width uint, desc string) Field {
+	return Field{Name: name, Shift: shift, Width: width, Access: ReadOnly, Description: desc}
+}
+
+func wt(name string, shift uint, width uint, desc string) Field {
+	return Field{Name: name, Shift: shift, Width: width, Access: WriteTrigger, Description: desc}
+}
+
+func rc(name string, shift uint, width uint, desc string) Field {
+	return Field{Name: name, Shift: shift, Width: width, Access: ReadClear, Description: desc}
+}
+
+// Registers that have the same layout in FSK/OOK and LoRa modes.
+var (
+	regFifo = Register{"RegFifo", RegFifo, 0x00, "FIFO read/write access", []Field{
+		rw("Fifo", 0, 8, "FIFO data input/output"),
+	}}
+	regFrfMsb = Register{"RegFrfMsb", RegFrfMsb, 0x6C, "RF Carrier Frequency, Most Significant Bits", []Field{
+		rw("Frf(23:16)", 0, 8, "RF carrier frequency, in units of FXOSC/2^19"),
+	}}
+	regFrfMid = Register{"RegFrfMid", RegFrfMid, 0x80, "RF Carrier Frequency, Intermediate Bits", []Field{
+		rw("Frf(15:8)", 0, 8, "RF carrier frequency"),
+	}}
+	regFrfLsb = Register{"RegFrfLsb", RegFrfLsb, 0x00, "RF Carrier Frequency, Least Significant Bits", []Field{
+		rw("Frf(7:0)", 0, 8, "RF carrier frequency; writing triggers a hop when FastHopOn is set"),
+	}}
+	regPaConfig = Register{"RegPaConfig", RegPaConfig, 0x4F, "PA selection and Output Power control", []Field{
+		rw("PaSelect", 7, 1, "0: RFO pin, 1: PA_BOOST pin"),
+		rw("MaxPower", 4, 3, "Pmax = 10.8 + 0.6*MaxPower dBm"),
+		rw("OutputPower", 0, 4, "Pout = 17 - (15 - OutputPower) dBm on PA_BOOST"),
+	}}
+	regPaRamp = Register{"RegPaRamp", RegPaRamp, 0x09, "Control of the PA ramp time, low phase noise PLL", []Field{
+		rw("ModulationShaping", 5, 2, "data shaping (FSK: Gaussian BT, OOK: filter cutoff)"),
+		rw("PaRamp", 0, 4, "rise/fall time of ramp up/down in FSK"),
+	}}
+	regOcp = Register{"RegOcp", RegOcp, 0x2B, "Over Current Protection control", []Field{
+		rw("OcpOn", 5, 1, "enables overload current protection for the PA"),
+		rw("OcpTrim", 0, 5, "trimming of OCP current"),
+	}}
+	regLna = Register{"RegLna", RegLna, 0x20, "LNA settings", []Field{
+		rw("LnaGain", 5, 3, "LNA gain setting (1 = max gain, 6 = min gain)"),
+		rw("LnaBoostLf", 3, 2, "low frequency (RFI_LF) LNA current adjustment"),
+		rw("LnaBoostHf", 0, 2, "high frequency (RFI_HF) LNA current adjustment"),
+	}}
+	regDioMapping1 = Register{"RegDioMapping1", RegDioMapping1, 0x00, "Mapping of pins DIO0 to DIO3", []Field{
+		rw("Dio0Mapping", 6, 2, "mapping of pin DIO0"),
+		rw("Dio1Mapping", 4, 2, "mapping of pin DIO1"),
+		rw("Dio2Mapping", 2, 2, "mapping of pin DIO2"),
+		rw("Dio3Mapping", 0, 2, "mapping of pin DIO3"),
+	}}
+	regDioMapping2 = Register{"RegDioMapping2", RegDioMapping2, 0x00, "Mapping of pins DIO4 and DIO5, ClkOut frequency", []Field{
+		rw("Dio4Mapping", 6, 2, "mapping of pin DIO4"),
+		rw("Dio5Mapping", 4, 2, "mapping of pin DIO5"),
+		rw("MapPreambleDetect", 0, 1, "interrupt on DIO4/DIO5: 0: Rssi, 1: PreambleDetect"),
+	}}
+	regVersion = Register{"RegVersion", RegVersion, 0x12, "Semtech ID relating the silicon revision", []Field{
+		ro("Version", 0, 8, "full revision number (bits 7-4) and metal mask revision (bits 3-0)"),
+	}}
+	regTcxo = Register{"RegTcxo", RegTcxo, 0x09, "TCXO or XTAL input setting", []Field{
+		rw("TcxoInputOn", 4, 1, "0: crystal oscillator, 1: external clipped sine TCXO on XTA pin"),
+	}}
+	regPaDac = Register{"RegPaDac", RegPaDac, 0x84, "Higher power settings of the PA", []Field{
+		rw("PaDac", 0, 3, "0x04: default, 0x07: +20 dBm on PA_BOOST"),
+	}}
+	regFormerTemp = Register{"RegFormerTemp", RegFormerTemp, 0x00, "Stored temperature during the former IQ Calibration", []Field{
+		ro("FormerTemp", 0, 8, "temperature saved during the latest IQ (RSSI and Image) calibration"),
+	}}
+	regAgcRef = Register{"RegAgcRef", RegAgcRef, 0x19, "Adjustment of the AGC thresholds", []Field{
+		rw("AgcReferenceLevel", 0, 6, "sets the floor reference for all AGC thresholds"),
+	}}
+	regAgcThresh1 = Register{"RegAgcThresh1", RegAgcThresh1, 0x0C, "AGC threshold 1", []Field{
+		rw("AgcStep1", 0, 5, "1st AGC threshold"),
+	}}
+	regAgcThresh2 = Register{"RegAgcThresh2", RegAgcThresh2, 0x4B, "AGC thresholds 2 and 3", []Field{
+		rw("AgcStep2", 4, 4, "2nd AGC threshold"),
+		rw("AgcStep3", 0, 4, "3rd AGC threshold"),
+	}}
+	regAgcThresh3 = Register{"RegAgcThresh3", RegAgcThresh3, 0xCC, "AGC thresholds 4 and 5", []Field{
+		rw("AgcStep4", 4, 4, "4th AGC threshold"),
+		rw("AgcStep5", 0, 4, "5th AGC threshold"),
+	}}
+	regPll = Register{"RegPll", RegPll, 0xD0, "Control of the PLL bandwidth", []Field{
+		rw("PllBandwidth", 6, 2, "PLL bandwidth: 75, 150, 225, or 300 kHz"),
+	}}
+)
+
+// FSKRegisters describes the SX1276 registers in FSK/OOK mode,
+// in order of address (data sheet section 6.2).
+var FSKRegisters = []Register{
+	regFifo,
+	{"RegOpMode", RegOpMode, 0x01, "Operating mode & LoRa / FSK selection", []Field{
+		rw("LongRangeMode", 7, 1, "0: FSK/OOK mode, 1: LoRa mode"),
+		rw("ModulationType", 5, 2, "0: FSK, 1: OOK"),
+		rw("LowFrequencyModeOn", 3, 1, "access low frequency mode registers"),
+		rw("Mode", 0, 3, "transceiver mode"),
+	}},
+	{"RegBitrateMsb", RegBitrateMsb, 0x1A, "Bit Rate setting, Most Significant Bits", []Field{
+		rw("BitRate(15:8)", 0, 8, "bit rate = FXOSC / (BitRate + BitRateFrac/16)"),
+	}},
+	{"RegBitrateLsb", RegBitrateLsb, 0x0B, "Bit Rate setting, Least Significant Bits", []Field{
+		rw("BitRate(7:0)", 0, 8, "bit rate, least significant bits"),
+	}},
+	{"RegFdevMsb", RegFdevMsb, 0x00, "Frequency Deviation setting, Most Significant Bits", []Field{
+		rw("Fdev(13:8)", 0, 6, "frequency deviation, in units of FXOSC/2^19"),
+	}},
+	{"RegFdevLsb", RegFdevLsb, 0x52, "Frequency Deviation setting, Least Significant Bits", []Field{
+		rw("Fdev(7:0)", 0, 8, "frequency deviation, least significant bits"),
+	}},
+	regFrfMsb,
+	regFrfMid,
+	regFrfLsb,
+	regPaConfig,
+	regPaRamp,
+	regOcp,
+	regLna,
+	{"RegRxConfig", RegRxConfig, 0x0E, "AFC, AGC, ctrl", []Field{
+		rw("RestartRxOnCollision", 7, 1, "restart the receiver automatically on collision"),
+		wt("RestartRxWithoutPllLock", 6, 1, "restart the receiver without waiting for PLL lock"),
+		wt("RestartRxWithPllLock", 5, 1, "restart the receiver after waiting for PLL lock"),
+		rw("AfcAutoOn", 4, 1, "perform AFC at each receiver startup"),
+		rw("AgcAutoOn", 3, 1, "LNA gain controlled by the AGC"),
+		rw("RxTrigger", 0, 3, "events triggering AGC and AFC"),
+	}},
+	{"RegRssiConfig", RegRssiConfig, 0x02, "RSSI", []Field{
+		rw("RssiOffset", 3, 5, "signed RSSI offset, in dB"),
+		rw("RssiSmoothing", 0, 3, "number of samples used for RSSI: 2^(RssiSmoothing+1)"),
+	}},
+	{"RegRssiCollision", RegRssiCollision, 0x0A, "RSSI Collision detector", []Field{
+		rw("RssiCollisionThreshold", 0, 8, "RSSI increase that detects a collision, in dB"),
+	}},
+	{"RegRssiThresh", RegRssiThresh, 0xFF, "RSSI Threshold control", []Field{
+		rw("RssiThreshold", 0, 8, "RSSI trigger level: -RssiThreshold/2 dBm"),
+	}},
+	{"RegRssiValue", RegRssiValue, 0x00, "RSSI value in dBm", []Field{
+		ro("RssiValue", 0, 8, "absolute RSSI: -RssiValue/2 dBm"),
+	}},
+	{"RegRxBw", RegRxBw, 0x15, "Channel Filter BW Control", []Field{
+		rw("RxBwMant", 3, 2, "channel filter bandwidth mantissa: 0: 16, 1: 20, 2: 24"),
+		rw("RxBwExp", 0, 3, "channel filter bandwidth exponent"),
+	}},
+	{"RegAfcBw", RegAfcBw, 0x0B, "AFC Channel Filter BW", []Field{
+		rw("RxBwMantAfc", 3, 2, "RxBwMant used during AFC"),
+		rw("RxBwExpAfc", 0, 3, "RxBwExp used during AFC"),
+	}},
+	{"RegOokPeak", RegOokPeak, 0x28, "OOK demodulator", []Field{
+		rw("BitSyncOn", 5, 1, "enables the bit synchronizer"),
+		rw("OokThreshType", 3, 2, "0: fixed, 1: peak, 2: average"),
+		rw("OokPeakThreshStep", 0, 3, "size of each decrement of the RSSI threshold"),
+	}},
+	{"RegOokFix", RegOokFix, 0x0C, "Threshold of the OOK demodulator", []Field{
+		rw("OokFixedThreshold", 0, 8, "fixed threshold, or floor threshold in peak mode, in dB"),
+	}},
+	{"RegOokAvg", RegOokAvg, 0x12, "Average of the OOK demodulator", []Field{
+		rw("OokPeakThreshDec", 5, 3, "period of decrement of the RSSI threshold"),
+		rw("OokAverageOffset", 2, 2, "static offset added to the threshold in average mode"),
+		rw("OokAverageThreshFilt", 0, 2, "filter coefficients in average mode"),
+	}},
+	{"RegRes17", 0x17, 0x47, "Reserved", nil},
+	{"RegRes18", 0x18, 0x32, "Reserved", nil},
+	{"RegRes19", 0x19, 0x3E, "Reserved", nil},
+	{"RegAfcFei", RegAfcFei, 0x00, "AFC and FEI control", []Field{
+		wt("AgcStart", 4, 1, "triggers an AGC sequence"),
+		wt("AfcClear", 1, 1, "clears the AFC value"),
+		rw("AfcAutoClearOn", 0, 1, "AFC register cleared at the beginning of automatic AFC"),
+	}},
+	{"RegAfcMsb", RegAfcMsb, 0x00, "Frequency correction value of the AFC, MSB", []Field{
+		rw("AfcValue(15:8)", 0, 8, "AFC correction, signed, in units of FXOSC/2^19"),
+	}},
+	{"RegAfcLsb", RegAfcLsb, 0x00, "Frequency correction value of the AFC, LSB", []Field{
+		rw("AfcValue(7:0)", 0, 8, "AFC correction, least significant bits"),
+	}},
+	{"RegFeiMsb", RegFeiMsb, 0x00, "Value of the calculated frequency error, MSB", []Field{
+		ro("FeiValue(15:8)", 0, 8, "measured frequency offset, signed, in units of FXOSC/2^19"),
+	}},
+	{"RegFeiLsb", RegFeiLsb, 0x00, "Value of the calculated frequency error, LSB", []Field{
+		ro("FeiValue(7:0)", 0, 8, "measured frequency offset, least significant bits"),
+	}},
+	{"RegPreambleDetect", RegPreambleDetect, 0x40, "Settings of the Preamble Detector", []Field{
+		rw("PreambleDetectorOn", 7, 1, "enables the preamble detector"),
+		rw("PreambleDetectorSize", 5, 2, "number of preamble bytes to detect: 1, 2, or 3"),
+		rw("PreambleDetectorTol", 0, 5, "number of chip errors tolerated over PreambleDetectorSize"),
+	}},
+	{"RegRxTimeout1", RegRxTimeout1, 0x00, "Timeout duration between Rx request and RSSI detection", []Field{
+		rw("TimeoutRxRssi", 0, 8, "timeout interrupt if RSSI interrupt doesn't occur"),
+	}},
+	{"RegRxTimeout2", RegRxTimeout2, 0x00, "Timeout duration between RSSI detection and PayloadReady", []Field{
+		rw("TimeoutRxPreamble", 0, 8, "timeout interrupt if PreambleDetect doesn't occur"),
+	}},
+	{"RegRxTimeout3", RegRxTimeout3, 0x00, "Timeout duration between RSSI detection and SyncAddress", []Field{
+		rw("TimeoutSignalSync", 0, 8, "timeout interrupt if SyncAddress doesn't occur"),
+	}},
+	{"RegRxDelay", RegRxDelay, 0x00, "Delay between Rx cycles", []Field{
+		rw("InterPacketRxDelay", 0, 8, "additional delay before an automatic receiver restart"),
+	}},
+	{"RegOsc", RegOsc, 0x07, "RC Oscillators Settings, CLK-OUT frequency", []Field{
+		wt("RcCalStart", 3, 1, "triggers the calibration of the RC oscillator"),
+		rw("ClkOut", 0, 3, "selects CLKOUT frequency"),
+	}},
+	{"RegPreambleMsb", RegPreambleMsb, 0x00, "Preamble length, MSB", []Field{
+		rw("PreambleSize(15:8)", 0, 8, "size of the preamble to be sent, in bytes"),
+	}},
+	{"RegPreambleLsb", RegPreambleLsb, 0x03, "Preamble length, LSB", []Field{
+		rw("PreambleSize(7:0)", 0, 8, "size of the preamble, least significant bits"),
+	}},
+	{"RegSyncConfig", RegSyncConfig, 0x93, "Sync Word Recognition control", []Field{
+		rw("AutoRestartRxMode", 6, 2, "receiver restart after PayloadReady or CrcOk"),
+		rw("PreamblePolarity", 5, 1, "0: 0xAA, 1: 0x55"),
+		rw("SyncOn", 4, 1, "enables the sync word generation and detection"),
+		rw("SyncSize", 0, 3, "size of the sync word: SyncSize + 1 bytes"),
+	}},
+	{"RegSyncValue1", RegSyncValue1, 0x55, "Sync Word byte 1", []Field{rw("SyncValue(63:56)", 0, 8, "1st byte of sync word")}},
+	{"RegSyncValue2", RegSyncValue2, 0x55, "Sync Word byte 2", []Field{rw("SyncValue(55:48)", 0, 8, "2nd byte of sync word")}},
+	{"RegSyncValue3", RegSyncValue3, 0x55, "Sync Word byte 3", []Field{rw("SyncValue(47:40)", 0, 8, "3rd byte of sync word")}},
+	{"RegSyncValue4", RegSyncValue4, 0x55, "Sync Word byte 4", []Field{rw("SyncValue(39:32)", 0, 8, "4th byte of sync word")}},
+	{"RegSyncValue5", RegSyncValue5, 0x55, "Sync Word byte 5", []Field{rw("SyncValue(31:24)", 0, 8, "5th byte of sync word")}},
+	{"RegSyncValue6", RegSyncValue6, 0x55, "Sync Word byte 6", []Field{rw("SyncValue(23:16)", 0, 8, "6th byte of sync word")}},
+	{"RegSyncValue7", RegSyncValue7, 0x55, "Sync Word byte 7", []Field{rw("SyncValue(15:8)", 0, 8, "7th byte of sync word")}},
+	{"RegSyncValue8", RegSyncValue8, 0x55, "Sync Word byte 8", []Field{rw("SyncValue(7:0)", 0, 8, "8th byte of sync word")}},
+	{"RegPacketConfig1", RegPacketConfig1, 0x90, "Packet mode settings", []Field{
+		rw("PacketFormat", 7, 1, "0: fixed length, 1: variable length"),
+		rw("DcFree", 5, 2, "0: none, 1: Manchester, 2: whitening"),
+		rw("CrcOn", 4, 1, "enables CRC calculation and check"),
+		rw("CrcAutoClearOff", 3, 1, "do not clear the FIFO when the CRC check fails"),
+		rw("AddressFiltering", 1, 2, "0: none, 1: node address, 2: node or broadcast address"),
+		rw("CrcWhiteningType", 0, 1, "0: CCITT CRC, 1: IBM CRC with alternate whitening"),
+	}},
+	{"RegPacketConfig2", RegPacketConfig2, 0x40, "Packet mode settings", []Field{
+		rw("DataMode", 6, 1, "0: continuous mode, 1: packet mode"),
+		rw("IoHomeOn", 5, 1, "enables the io-homecontrol compatibility mode"),
+		rw("IoHomePowerFrame", 4, 1, "reserved, linked to io-homecontrol"),
+		rw("BeaconOn", 3, 1, "enables the beacon mode in fixed packet format"),
+		rw("PayloadLength(10:8)", 0, 3, "packet length, most significant bits"),
+	}},
+	{"RegPayloadLength", RegPayloadLength, 0x40, "Payload length setting", []Field{
+		rw("PayloadLength(7:0)", 0, 8, "payload length (fixed format) or maximum length (variable format)"),
+	}},
+	{"RegNodeAdrs", RegNodeAdrs, 0x00, "Node address", []Field{
+		rw("NodeAddress", 0, 8, "node address used in address filtering"),
+	}},
+	{"RegBroadcastAdrs", RegBroadcastAdrs, 0x00, "Broadcast address", []Field{
+		rw("BroadcastAddress", 0, 8, "broadcast address used in address filtering"),
+	}},
+	{"RegFifoThresh", RegFifoThresh, 0x0F, "Fifo threshold, Tx start condition", []Field{
+		rw("TxStartCondition", 7, 1, "0: FifoLevel, 1: FifoEmpty goes low"),
+		rw("FifoThreshold", 0, 6, "threshold for the FifoLevel interrupt"),
+	}},
+	{"RegSeqConfig1", RegSeqConfig1, 0x00, "Top level Sequencer settings", []Field{
+		wt("SequencerStart", 7, 1, "starts the top level sequencer"),
+		wt("SequencerStop", 6, 1, "stops the top level sequencer"),
+		rw("IdleMode", 5, 1, "0: standby, 1: sleep"),
+		rw("FromStart", 3, 2, "transition from the sequencer start state"),
+		rw("LowPowerSelection", 2, 1, "0: SequencerOff, 1: idle state"),
+		rw("FromIdle", 1, 1, "0: to transmit, 1: to receive"),
+		rw("FromTransmit", 0, 1, "0: to low power on PacketSent, 1: to receive"),
+	}},
+	{"RegSeqConfig2", RegSeqConfig2, 0x00, "Top level Sequencer settings", []Field{
+		rw("FromReceive", 5, 3, "transition from the receive state"),
+		rw("FromRxTimeout", 3, 2, "transition from the receive state on RxTimeout"),
+		rw("FromPacketReceived", 0, 3, "transition from the packet received state"),
+	}},
+	{"RegTimerResol", RegTimerResol, 0x00, "Timer 1 and 2 resolution control", []Field{
+		rw("Timer1Resolution", 2, 2, "0: disabled, 1: 64 µs, 2: 4.1 ms, 3: 262 ms"),
+		rw("Timer2Resolution", 0, 2, "0: disabled, 1: 64 µs, 2: 4.1 ms, 3: 262 ms"),
+	}},
+	{"RegTimer1Coef", RegTimer1Coef, 0xF5, "Timer 1 setting", []Field{
+		rw("Timer1Coefficient", 0, 8, "multiplying coefficient for Timer 1"),
+	}},
+	{"RegTimer2Coef", RegTimer2Coef, 0x20, "Timer 2 setting", []Field{
+		rw("Timer2Coefficient", 0, 8, "multiplying coefficient for Timer 2"),
+	}},
+	{"RegImageCal", RegImageCal, 0x82, "Image calibration engine control", []Field{
+		rw("AutoImageCalOn", 7, 1, "calibrate automatically when the temperature changes"),
+		wt("ImageCalStart", 6, 1, "triggers the IQ and RSSI calibration"),
+		ro("ImageCalRunning", 5, 1, "IQ and RSSI calibration is in progress"),
+		ro("TempChange", 3, 1, "temperature changed more than TempThreshold"),
+		rw("TempThreshold", 1, 2, "temperature change threshold: 5, 10, 15, or 20 °C"),
+		rw("TempMonitorOff", 0, 1, "disables the temperature monitor"),
+	}},
+	{"RegTemp", RegTemp, 0x00, "Temperature Sensor value", []Field{
+		ro("TempValue", 0, 8, "measured temperature, signed, -1 °C per LSB"),
+	}},
+	{"RegLowBat", RegLowBat, 0x02, "Low Battery Indicator Settings", []Field{
+		rw("LowBatOn", 3, 1, "enables the low battery detector"),
+		rw("LowBatTrim", 0, 3, "trimming of the low battery threshold"),
+	}},
+	{"RegIrqFlags1", RegIrqFlags1, 0x80, "Status register: PLL Lock state, Timeout, RSSI", []Field{
+		ro("ModeReady", 7, 1, "operation mode requested in Mode is ready"),
+		ro("RxReady", 6, 1, "receive mode is ready"),
+		ro("TxReady", 5, 1, "transmit mode is ready"),
+		ro("PllLock", 4, 1, "PLL is locked"),
+		rc("Rssi", 3, 1, "RSSI exceeds RssiThreshold"),
+		ro("Timeout", 2, 1, "a timeout occurred"),
+		rc("PreambleDetect", 1, 1, "preamble detector found a preamble"),
+		rc("SyncAddressMatch", 0, 1, "sync word and address (if enabled) detected"),
+	}},
+	{"RegIrqFlags2", RegIrqFlags2, 0x40, "Status register: FIFO handling flags, Low Battery", []Field{
+		ro("FifoFull", 7, 1, "FIFO is full"),
+		ro("FifoEmpty", 6, 1, "FIFO is empty"),
+		ro("FifoLevel", 5, 1, "FIFO level exceeds FifoThreshold"),
+		rc("FifoOverrun", 4, 1, "FIFO overrun occurred"),
+		ro("PacketSent", 3, 1, "complete packet has been sent"),
+		ro("PayloadReady", 2, 1, "payload is ready"),
+		ro("CrcOk", 1, 1, "CRC of the payload is valid"),
+		rc("LowBat", 0, 1, "battery voltage below the low battery threshold"),
+	}},
+	regDioMapping1,
+	regDioMapping2,
+	regVersion,
+	{"RegPllHop", RegPllHop, 0x2D, "Control the fast frequency hopping mode", []Field{
+		rw("FastHopOn", 7, 1, "frequency changes when RegFrfLsb is written, without going through FS mode"),
+	}},
+	regTcxo,
+	regPaDac,
+	regFormerTemp,
+	{"RegBitRateFrac", RegBitRateFrac, 0x00, "Fractional part in the Bit Rate division ratio", []Field{
+		rw("BitRateFrac", 0, 4, "fractional part of the bit rate divider, in sixteenths"),
+	}},
+	regAgcRef,
+	regAgcThresh1,
+	regAgcThresh2,
+	regAgcThresh3,
+	regPll,
+}
+
+// LoRaRegisters describes the SX1276 registers in LoRa mode,
+// in order of address (data sheet section 6.4).
+var LoRaRegisters = []Register{
+	regFifo,
+	{"RegOpMode", RegOpMode, 0x01, "Operating mode & LoRa / FSK selection", []Field{
+		rw("LongRangeMode", 7, 1, "0: FSK/OOK mode, 1: LoRa mode"),
+		rw("AccessSharedReg", 6, 1, "access FSK registers 0x0D through 0x3F in LoRa mode"),
+		rw("LowFrequencyModeOn", 3, 1, "access low frequency mode registers"),
+		rw("Mode", 0, 3, "device mode"),
+	}},
+	regFrfMsb,
+	regFrfMid,
+	regFrfLsb,
+	regPaConfig,
+	{"RegPaRamp", RegPaRamp, 0x09, "Control of the PA ramp time, low phase noise PLL", []Field{
+		rw("PaRamp", 0, 4, "rise/fall time of ramp up/down"),
+	}},
+	regOcp,
+	regLna,
+	{"RegFifoAddrPtr", RegLoRaFifoAddrPtr, 0x00, "FIFO SPI pointer", []Field{
+		rw("FifoAddrPtr", 0, 8, "SPI interface address pointer in FIFO data buffer"),
+	}},
+	{"RegFifoTxBaseAddr", RegLoRaFifoTxBaseAddr, 0x80, "Start Tx data", []Field{
+		rw("FifoTxBaseAddr", 0, 8, "write base address in FIFO data buffer for the TX modulator"),
+	}},
+	{"RegFifoRxBaseAddr", RegLoRaFifoRxBaseAddr, 0x00, "Start Rx data", []Field{
+		rw("FifoRxBaseAddr", 0, 8, "read base address in FIFO data buffer for the RX demodulator"),
+	}},
+	{"RegFifoRxCurrentAddr", RegLoRaFifoRxCurrentAddr, 0x00, "Start address of last packet received", []Field{
+		ro("FifoRxCurrentAddr", 0, 8, "start address (in data buffer) of last packet received"),
+	}},
+	{"RegIrqFlagsMask", RegLoRaIrqFlagsMask, 0x00, "Optional IRQ flag mask", []Field{
+		rw("RxTimeoutMask", 7, 1, "masks the RxTimeout interrupt"),
+		rw("RxDoneMask", 6, 1, "masks the RxDone interrupt"),
+		rw("PayloadCrcErrorMask", 5, 1, "masks the PayloadCrcError interrupt"),
+		rw("ValidHeaderMask", 4, 1, "masks the ValidHeader interrupt"),
+		rw("TxDoneMask", 3, 1, "masks the TxDone interrupt"),
+		rw("CadDoneMask", 2, 1, "masks the CadDone interrupt"),
+		rw("FhssChangeChannelMask", 1, 1, "masks the FhssChangeChannel interrupt"),
+		rw("CadDetectedMask", 0, 1, "masks the CadDetected interrupt"),
+	}},
+	{"RegIrqFlags", RegLoRaIrqFlags, 0x00, "IRQ flags", []Field{
+		rc("RxTimeout", 7, 1, "timeout interrupt"),
+		rc("RxDone", 6, 1, "packet reception complete"),
+		rc("PayloadCrcError", 5, 1, "payload CRC error"),
+		rc("ValidHeader", 4, 1, "valid header received in Rx"),
+		rc("TxDone", 3, 1, "FIFO payload transmission complete"),
+		rc("CadDone", 2, 1, "CAD complete"),
+		rc("FhssChangeChannel", 1, 1, "FHSS change channel interrupt"),
+		rc("CadDetected", 0, 1, "valid LoRa signal detected during CAD"),
+	}},
+	{"RegRxNbBytes", RegLoRaRxNbBytes, 0x00, "Number of received bytes", []Field{
+		ro("FifoRxBytesNb", 0, 8, "number of payload bytes of latest packet received"),
+	}},
+	{"RegRxHeaderCntValueMsb", RegLoRaRxHeaderCntValueMsb, 0x00, "Number of valid headers received, MSB", []Field{
+		ro("ValidHeaderCnt(15:8)", 0, 8, "number of valid headers received since last Rx mode"),
+	}},
+	{"RegRxHeaderCntValueLsb", RegLoRaRxHeaderCntValueLsb, 0x00, "Number of valid headers received, LSB", []Field{
+		ro("ValidHeaderCnt(7:0)", 0, 8, "number of valid headers, least significant bits"),
+	}},
+	{"RegRxPacketCntValueMsb", RegLoRaRxPacketCntValueMsb, 0x00, "Number of valid packets received, MSB", []Field{
+		ro("ValidPacketCnt(15:8)", 0, 8, "number of valid packets received since last Rx mode"),
+	}},
+	{"RegRxPacketCntValueLsb", RegLoRaRxPacketCntValueLsb, 0x00, "Number of valid packets received, LSB", []Field{
+		ro("ValidPacketCnt(7:0)", 0, 8, "number of valid packets, least significant bits"),
+	}},
+	{"RegModemStat", RegLoRaModemStat, 0x10, "Live LoRa modem status", []Field{
+		ro("RxCodingRate", 5, 3, "coding rate of last header received"),
+		ro("ModemStatus", 0, 5, "modem clear, header info valid, RX on-going, signal synchronized, signal detected"),
+	}},
+	{"RegPktSnrValue", RegLoRaPktSnrValue, 0x00, "Estimation of last packet SNR", []Field{
+		ro("PacketSnr", 0, 8, "SNR of last packet received, signed, in units of 0.25 dB"),
+	}},
+	{"RegPktRssiValue", RegLoRaPktRssiValue, 0x00, "RSSI of last packet", []Field{
+		ro("PacketRssi", 0, 8, "RSSI of the latest packet received"),
+	}},
+	{"RegRssiValue", RegLoRaRssiValue, 0x00, "Current RSSI", []Field{
+		ro("Rssi", 0, 8, "current RSSI value"),
+	}},
+	{"RegHopChannel", RegLoRaHopChannel, 0x00, "FHSS start channel", []Field{
+		ro("PllTimeout", 7, 1, "PLL failed to lock while attempting a TX/RX/CAD operation"),
+		ro("CrcOnPayload", 6, 1, "CRC information extracted from the received packet header"),
+		ro("FhssPresentChannel", 0, 6, "current value of frequency hopping channel in use"),
+	}},
+	{"RegModemConfig1", RegLoRaModemConfig1, 0x72, "Modem PHY config 1", []Field{
+		rw("Bw", 4, 4, "signal bandwidth: 0 (7.8 kHz) through 9 (500 kHz)"),
+		rw("CodingRate", 1, 3, "error coding rate: 1 (4/5) through 4 (4/8)"),
+		rw("ImplicitHeaderModeOn", 0, 1, "0: explicit header mode, 1: implicit header mode"),
+	}},
+	{"RegModemConfig2", RegLoRaModemConfig2, 0x70, "Modem PHY config 2", []Field{
+		rw("SpreadingFactor", 4, 4, "SF rate: 6 through 12"),
+		rw("TxContinuousMode", 3, 1, "send multiple packets across the FIFO"),
+		rw("RxPayloadCrcOn", 2, 1, "enables CRC generation and check on payload"),
+		rw("SymbTimeout(9:8)", 0, 2, "RX timeout, most significant bits"),
+	}},
+	{"RegSymbTimeoutLsb", RegLoRaSymbTimeoutLsb, 0x64, "Receiver timeout value", []Field{
+		rw("SymbTimeout(7:0)", 0, 8, "RX timeout in single mode, in number of symbols"),
+	}},
+	{"RegPreambleMsb", RegLoRaPreambleMsb, 0x00, "Size of preamble, MSB", []Field{
+		rw("PreambleLength(15:8)", 0, 8, "preamble length, most significant bits"),
+	}},
+	{"RegPreambleLsb", RegLoRaPreambleLsb, 0x08, "Size of preamble, LSB", []Field{
+		rw("PreambleLength(7:0)", 0, 8, "preamble length = PreambleLength + 4.25 symbols"),
+	}},
+	{"RegPayloadLength", RegLoRaPayloadLength, 0x01, "LoRa payload length", []Field{
+		rw("PayloadLength", 0, 8, "payload length in bytes; required in implicit header mode"),
+	}},
+	{"RegMaxPayloadLength", RegLoRaMaxPayloadLength, 0xFF, "LoRa maximum payload length", []Field{
+		rw("PayloadMaxLength", 0, 8, "maximum payload length; longer packets are discarded"),
+	}},
+	{"RegHopPeriod", RegLoRaHopPeriod, 0x00, "FHSS hop period", []Field{
+		rw("FreqHoppingPeriod", 0, 8, "symbol periods between frequency hops (0 = disabled)"),
+	}},
+	{"RegFifoRxByteAddr", RegLoRaFifoRxByteAddr, 0x00, "Address of last byte written in FIFO", []Field{
+		ro("FifoRxByteAddrPtr", 0, 8, "current value of RX data buffer pointer"),
+	}},
+	{"RegModemConfig3", RegLoRaModemConfig3, 0x00, "Modem PHY config 3", []Field{
+		rw("LowDataRateOptimize", 3, 1, "mandatory when the symbol length exceeds 16 ms"),
+		rw("AgcAutoOn", 2, 1, "0: LNA gain set by LnaGain, 1: LNA gain set by the AGC"),
+	}},
+	{"RegFeiMsb", RegLoRaFeiMsb, 0x00, "Estimated frequency error, MSB", []Field{
+		ro("FreqError(19:16)", 0, 4, "estimated frequency error, most significant bits"),
+	}},
+	{"RegFeiMid", RegLoRaFeiMid, 0x00, "Estimated frequency error, middle bits", []Field{
+		ro("FreqError(15:8)", 0, 8, "estimated frequency error, middle bits"),
+	}},
+	{"RegFeiLsb", RegLoRaFeiLsb, 0x00, "Estimated frequency error, LSB", []Field{
+		ro("FreqError(7:0)", 0, 8, "estimated frequency error, least significant bits"),
+	}},
+	{"RegRssiWideband", RegLoRaRssiWideband, 0x00, "Wideband RSSI measurement", []Field{
+		ro("RssiWideband", 0, 8, "wideband RSSI measurement, used to generate random numbers"),
+	}},
+	{"RegIfFreq2", RegLoRaIfFreq2, 0x20, "IF frequency optimization (errata section 2.3)", []Field{
+		rw("IfFreq2", 0, 8, "IF frequency optimization"),
+	}},
+	{"RegIfFreq1", RegLoRaIfFreq1, 0x00, "IF frequency optimization (errata section 2.3)", []Field{
+		rw("IfFreq1", 0, 8, "IF frequency optimization"),
+	}},
+	{"RegDetectOptimize", RegLoRaDetectOptimize, 0xC3, "LoRa detection optimize for SF6", []Field{
+		rw("AutomaticIFOn", 7, 1, "automatic IF optimization (errata section 2.3)"),
+		rw("DetectionOptimize", 0, 3, "0x03: SF7 to SF12, 0x05: SF6"),
+	}},
+	{"RegInvertIQ", RegLoRaInvertIQ, 0x27, "Invert LoRa I and Q signals", []Field{
+		rw("InvertIQRX", 6, 1, "invert the LoRa I and Q signals in RX path"),
+		rw("InvertIQTX", 0, 1, "invert the LoRa I and Q signals in TX path"),
+	}},
+	{"RegHighBwOptimize1", RegLoRaHighBwOptimize1, 0x03, "Sensitivity optimization for 500 kHz bandwidth (errata section 2.1)", []Field{
+		rw("HighBwOptimize1", 0, 8, "0x02 for 500 kHz bandwidth, 0x03 otherwise"),
+	}},
+	{"RegDetectionThreshold", RegLoRaDetectionThreshold, 0x0A, "LoRa detection threshold for SF6", []Field{
+		rw("DetectionThreshold", 0, 8, "0x0A: SF7 to SF12, 0x0C: SF6"),
+	}},
+	{"RegSyncWord", RegLoRaSyncWord, 0x12, "LoRa sync word", []Field{
+		rw("SyncWord", 0, 8, "LoRa sync word; 0x34 is reserved for LoRaWAN networks"),
+	}},
+	{"RegHighBwOptimize2", RegLoRaHighBwOptimize2, 0x52, "Sensitivity optimization for 500 kHz bandwidth (errata section 2.1)", []Field{
+		rw("HighBwOptimize2", 0, 8, "0x64 or 0x7F for 500 kHz bandwidth"),
+	}},
+	{"RegInvertIQ2", RegLoRaInvertIQ2, 0x1D, "Optimize for inverted IQ", []Field{
+		rw("InvertIQ2", 0, 8, "0x19 when InvertIQ is set, 0x1D otherwise"),
+	}},
+	regDioMapping1,
+	regDioMapping2,
+	regVersion,
+	regTcxo,
+	regPaDac,
+	regFormerTemp,
+	regAgcRef,
+	regAgcThresh1,
+	regAgcThresh2,
+	regAgcThresh3,
+	regPll,
+}
+
+// RegisterMap returns the register descriptions for the mode
+// indicated by RegOpMode in the given configuration.
+func RegisterMap(config []byte) []Register {
+	if len(config) > RegOpMode && config[RegOpMode]&LoRaMode != 0 {
+		return LoRaRegisters
+	}
+	return FSKRegisters
+}
+
+// LookupRegister returns the register with the given address in regs, or nil.
+func LookupRegister(regs []Register, addr byte) *Register {
+	for i := range regs {
+		if regs[i].Address == addr {
+			return &regs[i]
+		}
+	}
+	return nil
+}
+
+// FieldValue is the value of a field in a register snapshot.
+type FieldValue struct {
+	Field *Field
+	Value byte
+}
+
+// RegisterValue is the value of a register in a register snapshot,
+// decoded into its fields.
+type RegisterValue struct {
+	Register *Register
+	Value    byte
+	Fields   []FieldValue
+}
+
+// DecodeConfiguration decodes the registers present in a configuration
+// snapshot, such as the result of ReadConfiguration, into named fields.
+// RegFifo is skipped.
+// The register tables describe the SX1276, so snapshots whose RegVersion
+// identifies another chip (such as the SX1272) are rejected.
+func DecodeConfiguration(config []byte) ([]RegisterValue, error) {
+	if c := configurationChip(config); c != nil && c != sx1276 {
+		return nil, fmt.Errorf("cannot decode %s configuration: register tables describe the %s", c.name, sx1276.name)
+	}
+	regs := RegisterMap(config)
+	var values []RegisterValue
+	for i := range regs {
+		reg := &regs[i]
+		addr := int(reg.Address)
+		if addr < ConfigurationStart || addr >= len(config) {
+			continue
+		}
+		v := RegisterValue{Register: reg, Value: config[addr]}
+		for j := range reg.Fields {
+			f := &reg.Fields[j]
+			v.Fields = append(v.Fields, FieldValue{Field: f, Value: f.Value(config[addr])})
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// configurationChip returns the chip identified by the RegVersion value
+// in a configuration snapshot, or nil if it is absent or unknown.
+func configurationChip(config []byte) *chip {
+	if len(config) <= RegVersion {
+		return nil
+	}
+	v := config[RegVersion]
+	return chipForVersion(uint16(v>>4)<<8 | uint16(v&0xF))
+}
+
+// FormatConfiguration writes a human-readable decoding of a
+// configuration snapshot to w. Registers whose value differs from
+// the reset value are marked.
+func FormatConfiguration(w io.Writer, config []byte) error {
+	values, err := DecodeConfiguration(config)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		reg := v.Register
+		_, err := fmt.Fprintf(w, "%02X  %-22s %02X  %08b", reg.Address, reg.Name, v.Value, v.Value)
+		if err != nil {
+			return err
+		}
+		if v.Value != reg.Reset {
+			_, err = fmt.Fprintf(w, "  (reset %02X)", reg.Reset)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "  %s\n", reg.Description)
+		if err != nil {
+			return err
+		}
+		for _, f := range v.Fields {
+			if f.Field.Width == 8 {
+				// The field is the whole register.
+				continue
+			}
+			_, err = fmt.Fprintf(w, "      %-24s %-3s %3d  %s\n", f.Field.Name, f.Field.Access, f.Value, f.Field.Description)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package rfm95
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func checkRegisterMap(t *testing.T, name string, regs []Register) {
+	for i, reg := range regs {
+		if i > 0 && reg.Address <= regs[i-1].Address {
+			t.Errorf("%s: %s (%02X) is out of order", name, reg.Name, reg.Address)
+		}
+		used := byte(0)
+		for _, f := range reg.Fields {
+			if f.Width == 0 || f.Shift+f.Width > 8 {
+				t.Errorf("%s: %s.%s has invalid position", name, reg.Name, f.Name)
+			}
+			if used&f.Mask() != 0 {
+				t.Errorf("%s: %s.%s overlaps another field", name, reg.Name, f.Name)
+			}
+			used |= f.Mask()
+		}
+	}
+}
+
+func TestRegisterMaps(t *testing.T) {
+	checkRegisterMap(t, "FSK", FSKRegisters)
+	checkRegisterMap(t, "LoRa", LoRaRegisters)
+	for addr := ConfigurationStart; addr < len(resetConfiguration); addr++ {
+		reg := LookupRegister(FSKRegisters, byte(addr))
+		if reg == nil {
+			t.Errorf("no FSK register at %02X", addr)
+			continue
+		}
+		if reg.Reset != resetConfiguration[addr] {
+			t.Errorf("%s reset value is %02X, want %02X", reg.Name, reg.Reset, resetConfiguration[addr])
+		}
+	}
+}
+
+func TestDecodeConfiguration(t *testing.T) {
+	config := ResetConfiguration()
+	config[RegOpMode] = FskOokMode | ModulationTypeOOK | LowFrequencyModeOn | ReceiverMode
+	values, err := DecodeConfiguration(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != len(config)-ConfigurationStart {
+		t.Fatalf("decoded %d registers, want %d", len(values), len(config)-ConfigurationStart)
+	}
+	v := values[0]
+	if v.Register.Name != "RegOpMode" || v.Value != config[RegOpMode] {
+		t.Fatalf("first decoded register is %s = %02X", v.Register.Name, v.Value)
+	}
+	want := map[string]byte{
+		"LongRangeMode":      0,
+		"ModulationType":     1,
+		"LowFrequencyModeOn": 1,
+		"Mode":               ReceiverMode,
+	}
+	for _, f := range v.Fields {
+		if f.Value != want[f.Field.Name] {
+			t.Errorf("%s == %d, want %d", f.Field.Name, f.Value, want[f.Field.Name])
+		}
+	}
+	config[RegOpMode] |= LoRaMode
+	if v, _ := DecodeConfiguration(config); v[8].Register.Name != "RegFifoAddrPtr" {
+		t.Errorf("LoRa configuration decoded register 0D as %s", v[8].Register.Name)
+	}
+	// The field layouts of the SX1272 differ, so its snapshots are rejected.
+	config = sx1272.configuration(resetConfiguration)
+	if _, err := DecodeConfiguration(config); err == nil {
+		t.Errorf("SX1272 configuration was decoded")
+	}
+	if err := FormatConfiguration(io.Discard, config); err == nil {
+		t.Errorf("SX1272 configuration was formatted")
+	}
+	if _, err := NewProfile("sx1272", config); err == nil {
+		t.Errorf("profile was created from SX1272 configuration")
+	}
+}
+
+func TestFormatConfiguration(t *testing.T) {
+	config := ResetConfiguration()
+	config[RegRxBw] = RxBwMant20 | 4<<RxBwExpShift
+	var buf bytes.Buffer
+	err := FormatConfiguration(&buf, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"12  RegRxBw                0C  00001100  (reset 15)  Channel Filter BW Control\n",
+		"      RxBwMant                 rw    1  ",
+		"      RxBwExp                  rw    4  ",
+		"01  RegOpMode              01  00000001  Operating mode",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("formatted configuration does not contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+// packetQueueSize is the number of received packets buffered by a client.
+// Older packets are discarded when the queue is full.
+const packetQueueSize = 64
+
+// Client is a connection to a radio server.
+// It implements radio.Interface.
+// A Client can be used by several goroutines, but like a Radio
+// it has a single error state, which they share.
+type Client struct {
+	network string
+	address string
+
+	conn net.Conn
+	mu   sync.Mutex // serializes requests
+	enc  *json.Encoder
+	id   uint64
+
+	responses chan Response
+	packets   chan Response
+
+	state      sync.Mutex // guards the following fields
+	subscribed bool
+	name       string
+	err        error
+}
+
+// Dial connects to the radio server at the given address.
+// The network must be "unix", "tcp", or a variant supported by net.Dial.
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		network:   network,
+		address:   address,
+		conn:      conn,
+		enc:       json.NewEncoder(conn),
+		responses: make(chan Response),
+		packets:   make(chan Response, packetQueueSize),
+	}
+	go c.read()
+	return c, nil
+}
+
+// read dispatches responses and packet events from the server.
+func (c *Client) read() {
+	defer close(c.responses)
+	dec := json.NewDecoder(bufio.NewReader(c.conn))
+	for {
+		var resp Response
+		err := dec.Decode(&resp)
+		if err != nil {
+			return
+		}
+		if resp.Event != EventPacket {
+			c.responses <- resp
+			continue
+		}
+		select {
+		case c.packets <- resp:
+		default:
+			// Discard the oldest packet to make room.
+			select {
+			case <-c.packets:
+			default:
+			}
+			c.packets <- resp
+		}
+	}
+}
+
+// call sends a request and waits for its response.
+func (c *Client) call(req Request) Response {
+	if c.Error() != nil {
+		return Response{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.id++
+	req.ID = c.id
+	err := c.enc.Encode(req)
+	if err != nil {
+		c.SetError(err)
+		return Response{}
+	}
+	resp, ok := <-c.responses
+	switch {
+	case !ok:
+		c.SetError(io.ErrUnexpectedEOF)
+	case resp.ID != req.ID:
+		c.SetError(fmt.Errorf("%s: response ID %d, expected %d", req.Op, resp.ID, req.ID))
+	case resp.Error != "":
+		c.SetError(RemoteError{Op: req.Op, Msg: resp.Error})
+	}
+	return resp
+}
+
+// Init initializes the radio device.
+func (c *Client) Init(frequency uint32) {
+	c.call(Request{Op: OpInit, Value: int64(frequency)})
+}
+
+// Reset resets the radio device.
+func (c *Client) Reset() {
+	c.call(Request{Op: OpReset})
+}
+
+// Close closes the connection to the server.
+// The radio itself remains open.
+func (c *Client) Close() {
+	_ = c.conn.Close()
+}
+
+// Frequency returns the radio's current frequency, in Hertz.
+func (c *Client) Frequency() uint32 {
+	return uint32(c.call(Request{Op: OpFrequency}).Value)
+}
+
+// SetFrequency sets the radio to the given frequency, in Hertz.
+func (c *Client) SetFrequency(freq uint32) {
+	c.call(Request{Op: OpSetFrequency, Value: int64(freq)})
+}
+
+// Bitrate returns the radio's bit rate, in bps.
+func (c *Client) Bitrate() uint32 {
+	return uint32(c.call(Request{Op: OpBitrate}).Value)
+}
+
+// SetBitrate sets the radio's bit rate to the given rate, in bps.
+func (c *Client) SetBitrate(br uint32) {
+	c.call(Request{Op: OpSetBitrate, Value: int64(br)})
+}
+
+// OutputPower returns the radio's output power, in dBm.
+func (c *Client) OutputPower() int {
+	return int(c.call(Request{Op: OpOutputPower}).Value)
+}
+
+// SetOutputPower sets the radio's output power, in dBm.
+func (c *Client) SetOutputPower(dBm int) {
+	c.call(Request{Op: OpSetOutputPower, Value: int64(dBm)})
+}
+
+// Settings reads the radio's current FSK/OOK settings.
+func (c *Client) Settings() rfm95.Settings {
+	resp := c.call(Request{Op: OpSettings})
+	if resp.Settings == nil {
+		return rfm95.Settings{}
+	}
+	return *resp.Settings
+}
+
+// ApplySettings writes the given FSK/OOK settings to the radio.
+func (c *Client) ApplySettings(s rfm95.Settings) {
+	c.call(Request{Op: OpApplySettings, Settings: &s})
+}
+
+// Send transmits the given packet.
+func (c *Client) Send(data []byte) {
+	c.call(Request{Op: OpSend, Data: data})
+}
+
+// Subscribe asks the server to forward received packets to the client.
+// Receive calls it automatically. Packets received before subscribing are not seen.
+func (c *Client) Subscribe() {
+	c.state.Lock()
+	subscribed := c.subscribed
+	c.state.Unlock()
+	if subscribed {
+		return
+	}
+	c.call(Request{Op: OpSubscribe})
+	c.state.Lock()
+	defer c.state.Unlock()
+	c.subscribed = c.err == nil
+}
+
+// Receive waits with the given timeout for a packet forwarded by the server.
+// It returns the packet and the associated RSSI,
+// or nil if no packet arrives before the timeout.
+func (c *Client) Receive(timeout time.Duration) ([]byte, int) {
+	c.Subscribe()
+	if c.Error() != nil {
+		return nil, 0
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case p := <-c.packets:
+		return p.Data, p.RSSI
+	case <-t.C:
+		return nil, 0
+	}
+}
+
+// SendAndReceive transmits the given packet,
+// then waits with the given timeout for a packet.
+// Packets that arrived before the transmission are discarded.
+func (c *Client) SendAndReceive(data []byte, timeout time.Duration) ([]byte, int) {
+	c.Subscribe()
+	for len(c.packets) != 0 {
+		<-c.packets
+	}
+	c.Send(data)
+	if c.Error() != nil {
+		return nil, 0
+	}
+	return c.Receive(timeout)
+}
+
+// State returns the radio's current state.
+func (c *Client) State() string {
+	return c.call(Request{Op: OpState}).Text
+}
+
+// Error returns the error state of the client.
+func (c *Client) Error() error {
+	c.state.Lock()
+	defer c.state.Unlock()
+	return c.err
+}
+
+// SetError sets the error state of the client.
+func (c *Client) SetError(err error) {
+	c.state.Lock()
+	defer c.state.Unlock()
+	c.err = err
+}
+
+// Name returns the name of the remote radio device.
+func (c *Client) Name() string {
+	c.state.Lock()
+	name := c.name
+	c.state.Unlock()
+	if name != "" {
+		return name
+	}
+	name = c.call(Request{Op: OpName}).Text
+	c.state.Lock()
+	defer c.state.Unlock()
+	c.name = name
+	return name
+}
+
+// Device returns the address of the server.
+func (c *Client) Device() string {
+	return c.network + ":" + c.address
+}
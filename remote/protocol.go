@@ -0,0 +1,71 @@
+// Package remote shares a radio among several processes.
+// A server owns the radio device and accepts connections on a
+// Unix or TCP socket; Client implements radio.Interface on top of
+// such a connection.
+//
+// The protocol consists of JSON-encoded Request and Response objects,
+// one per line. Each request receives a response with the same ID.
+// After a "subscribe" request, the server also sends packet events
+// (responses with ID 0) for every packet the radio receives.
+package remote
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ecc1/rfm95"
+)
+
+// Operations.
+const (
+	OpInit           = "init"
+	OpReset          = "reset"
+	OpFrequency      = "frequency"
+	OpSetFrequency   = "set_frequency"
+	OpBitrate        = "bitrate"
+	OpSetBitrate     = "set_bitrate"
+	OpOutputPower    = "output_power"
+	OpSetOutputPower = "set_output_power"
+	OpSettings       = "settings"
+	OpApplySettings  = "apply_settings"
+	OpState          = "state"
+	OpName           = "name"
+	OpSend           = "send"
+	OpSubscribe      = "subscribe"
+	OpUnsubscribe    = "unsubscribe"
+)
+
+// EventPacket identifies a received packet.
+const EventPacket = "packet"
+
+// Request is a message from a client to the server.
+type Request struct {
+	ID       uint64          `json:"id"`
+	Op       string          `json:"op"`
+	Data     []byte          `json:"data,omitempty"`
+	Value    int64           `json:"value,omitempty"`
+	Settings *rfm95.Settings `json:"settings,omitempty"`
+}
+
+// Response is a message from the server to a client.
+type Response struct {
+	ID       uint64          `json:"id,omitempty"`
+	Event    string          `json:"event,omitempty"`
+	Data     []byte          `json:"data,omitempty"`
+	RSSI     int             `json:"rssi,omitempty"`
+	Time     *time.Time      `json:"time,omitempty"`
+	Value    int64           `json:"value,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	Settings *rfm95.Settings `json:"settings,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// RemoteError is an error reported by the server.
+type RemoteError struct {
+	Op  string
+	Msg string
+}
+
+func (e RemoteError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Msg)
+}
@@ -0,0 +1,184 @@
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ecc1/radio"
+	"github.com/ecc1/rfm95"
+)
+
+var (
+	// Ensure that *Client implements the radio.Interface interface.
+	_ radio.Interface = (*Client)(nil)
+
+	// Ensure that *rfm95.Radio can be served.
+	_ Device = (*rfm95.Radio)(nil)
+)
+
+// fakeDevice echoes each sent packet back, reversed, on the next Receive.
+type fakeDevice struct {
+	mu      sync.Mutex
+	freq    uint32
+	bitrate uint32
+	power   int
+	pending [][]byte
+	err     error
+}
+
+func (d *fakeDevice) Init(freq uint32)         { d.freq = freq }
+func (d *fakeDevice) Reset()                   {}
+func (d *fakeDevice) Close()                   {}
+func (d *fakeDevice) Frequency() uint32        { return d.freq }
+func (d *fakeDevice) Bitrate() uint32          { return d.bitrate }
+func (d *fakeDevice) SetBitrate(br uint32)     { d.bitrate = br }
+func (d *fakeDevice) OutputPower() int         { return d.power }
+func (d *fakeDevice) Settings() rfm95.Settings { return rfm95.Settings{Frequency: d.freq} }
+func (d *fakeDevice) State() string            { return "Sleep" }
+func (d *fakeDevice) Error() error             { return d.err }
+func (d *fakeDevice) SetError(err error)       { d.err = err }
+func (d *fakeDevice) Name() string             { return "fake" }
+func (d *fakeDevice) Device() string           { return "none" }
+
+func (d *fakeDevice) SetFrequency(freq uint32) {
+	if freq < 100000000 {
+		d.err = fmt.Errorf("frequency %d out of range", freq)
+		return
+	}
+	d.freq = freq
+}
+
+func (d *fakeDevice) SetOutputPower(dBm int) { d.power = dBm }
+
+func (d *fakeDevice) ApplySettings(s rfm95.Settings) { d.freq = s.Frequency }
+
+func (d *fakeDevice) Send(data []byte) {
+	p := make([]byte, len(data))
+	for i, b := range data {
+		p[len(p)-1-i] = b
+	}
+	d.mu.Lock()
+	d.pending = append(d.pending, p)
+	d.mu.Unlock()
+}
+
+func (d *fakeDevice) Receive(timeout time.Duration) ([]byte, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.pending) == 0 {
+		d.mu.Unlock()
+		time.Sleep(time.Millisecond)
+		d.mu.Lock()
+		return nil, 0
+	}
+	p := d.pending[0]
+	d.pending = d.pending[1:]
+	return p, -42
+}
+
+func (d *fakeDevice) SendAndReceive(data []byte, timeout time.Duration) ([]byte, int) {
+	d.Send(data)
+	return d.Receive(timeout)
+}
+
+func startServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = NewServer(&fakeDevice{}).Serve(l) }()
+	return l
+}
+
+func TestClient(t *testing.T) {
+	l := startServer(t)
+	defer l.Close()
+	c, err := Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Init(916600000)
+	if f := c.Frequency(); f != 916600000 || c.Error() != nil {
+		t.Errorf("Frequency() == %d, %v, want 916600000", f, c.Error())
+	}
+	c.SetBitrate(16384)
+	c.SetOutputPower(17)
+	if br, p := c.Bitrate(), c.OutputPower(); br != 16384 || p != 17 {
+		t.Errorf("Bitrate(), OutputPower() == %d, %d, want 16384, 17", br, p)
+	}
+	if s := c.Settings(); s.Frequency != 916600000 {
+		t.Errorf("Settings().Frequency == %d, want 916600000", s.Frequency)
+	}
+	if n := c.Name(); n != "fake" {
+		t.Errorf("Name() == %q, want \"fake\"", n)
+	}
+	c.SetFrequency(1)
+	if _, ok := c.Error().(RemoteError); !ok {
+		t.Errorf("SetFrequency(1) error == %v, want RemoteError", c.Error())
+	}
+	c.SetError(nil)
+	p, rssi := c.SendAndReceive([]byte{1, 2, 3}, time.Second)
+	if !bytes.Equal(p, []byte{3, 2, 1}) || rssi != -42 {
+		t.Errorf("SendAndReceive == % X, %d, want 03 02 01, -42", p, rssi)
+	}
+	// A timeout is not an error, as with a local radio.
+	p, _ = c.Receive(10 * time.Millisecond)
+	if p != nil || c.Error() != nil {
+		t.Errorf("Receive == % X, %v, want nil, nil", p, c.Error())
+	}
+}
+
+func TestSharedReceive(t *testing.T) {
+	l := startServer(t)
+	defer l.Close()
+	var clients []*Client
+	for i := 0; i < 3; i++ {
+		c, err := Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		c.Subscribe()
+		clients = append(clients, c)
+	}
+	clients[0].Send([]byte{0xA7, 0x12})
+	for i, c := range clients {
+		p, _ := c.Receive(time.Second)
+		if !bytes.Equal(p, []byte{0x12, 0xA7}) {
+			t.Errorf("client %d: Receive == % X, %v, want 12 A7", i, p, c.Error())
+		}
+	}
+}
+
+func TestConcurrentClient(t *testing.T) {
+	l := startServer(t)
+	defer l.Close()
+	c, err := Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Init(916600000)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if n := c.Name(); n != "fake" {
+					t.Errorf("Name() == %q, want \"fake\"", n)
+				}
+				c.Receive(time.Millisecond)
+				if f := c.Frequency(); f != 916600000 || c.Error() != nil {
+					t.Errorf("Frequency() == %d, %v, want 916600000", f, c.Error())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
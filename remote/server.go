@@ -0,0 +1,237 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ecc1/radio"
+	"github.com/ecc1/rfm95"
+)
+
+// Device is the radio interface used by the server.
+// It is satisfied by *rfm95.Radio.
+type Device interface {
+	radio.Interface
+	Bitrate() uint32
+	SetBitrate(uint32)
+	OutputPower() int
+	SetOutputPower(int)
+	Settings() rfm95.Settings
+	ApplySettings(rfm95.Settings)
+}
+
+// pollInterval bounds the time a request waits
+// while the server is listening for packets.
+const pollInterval = 100 * time.Millisecond
+
+// sendQueueSize is the number of responses and packet events
+// buffered for each connection. Packet events for a connection
+// whose queue is full are discarded.
+const sendQueueSize = 64
+
+// Server gives clients shared access to a radio device.
+type Server struct {
+	dev      Device
+	commands chan command
+	start    sync.Once
+
+	mu          sync.Mutex
+	subscribers map[*conn]bool
+}
+
+type command struct {
+	req   Request
+	reply chan Response
+}
+
+// conn is a client connection. Responses and packet events are queued
+// and written by a separate goroutine, so a slow client cannot delay others.
+type conn struct {
+	c   net.Conn
+	out chan Response
+}
+
+func newConn(nc net.Conn) *conn {
+	c := &conn{c: nc, out: make(chan Response, sendQueueSize)}
+	go c.send()
+	return c
+}
+
+// send writes queued responses until the queue is closed,
+// then closes the connection. After a write error,
+// it closes the connection early and discards the rest.
+func (c *conn) send() {
+	defer func() { _ = c.c.Close() }()
+	enc := json.NewEncoder(c.c)
+	var err error
+	for resp := range c.out {
+		if err != nil {
+			continue
+		}
+		err = enc.Encode(resp)
+		if err != nil {
+			_ = c.c.Close()
+		}
+	}
+}
+
+// NewServer returns a server for the given device.
+func NewServer(dev Device) *Server {
+	return &Server{
+		dev:         dev,
+		commands:    make(chan command),
+		subscribers: make(map[*conn]bool),
+	}
+}
+
+// Serve accepts connections on the listener until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.start.Do(func() { go s.run() })
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(nc net.Conn) {
+	c := newConn(nc)
+	defer func() {
+		// Once unsubscribed, c receives no more broadcasts.
+		s.subscribe(c, false)
+		close(c.out)
+	}()
+	dec := json.NewDecoder(bufio.NewReader(nc))
+	for {
+		var req Request
+		err := dec.Decode(&req)
+		if err != nil {
+			return
+		}
+		var resp Response
+		switch req.Op {
+		case OpSubscribe:
+			s.subscribe(c, true)
+		case OpUnsubscribe:
+			s.subscribe(c, false)
+		default:
+			reply := make(chan Response)
+			s.commands <- command{req: req, reply: reply}
+			resp = <-reply
+		}
+		resp.ID = req.ID
+		c.out <- resp
+	}
+}
+
+func (s *Server) subscribe(c *conn, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if on {
+		s.subscribers[c] = true
+	} else {
+		delete(s.subscribers, c)
+	}
+}
+
+func (s *Server) listening() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) != 0
+}
+
+func (s *Server) broadcast(resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.subscribers {
+		select {
+		case c.out <- resp:
+		default:
+			log.Printf("%v: send queue full; discarding packet", c.c.RemoteAddr())
+		}
+	}
+}
+
+// run owns the device. It executes requests one at a time,
+// and listens for packets in between while there are subscribers.
+func (s *Server) run() {
+	for {
+		if !s.listening() {
+			s.execute(<-s.commands)
+			continue
+		}
+		select {
+		case cmd := <-s.commands:
+			s.execute(cmd)
+		default:
+			s.receive()
+		}
+	}
+}
+
+func (s *Server) receive() {
+	data, rssi := s.dev.Receive(pollInterval)
+	err := s.dev.Error()
+	if err != nil {
+		log.Printf("receive: %v", err)
+		s.dev.SetError(nil)
+	}
+	if data == nil {
+		return
+	}
+	t := time.Now()
+	s.broadcast(Response{Event: EventPacket, Data: data, RSSI: rssi, Time: &t})
+}
+
+func (s *Server) execute(cmd command) {
+	req := cmd.req
+	dev := s.dev
+	var resp Response
+	switch req.Op {
+	case OpInit:
+		dev.Init(uint32(req.Value))
+	case OpReset:
+		dev.Reset()
+	case OpFrequency:
+		resp.Value = int64(dev.Frequency())
+	case OpSetFrequency:
+		dev.SetFrequency(uint32(req.Value))
+	case OpBitrate:
+		resp.Value = int64(dev.Bitrate())
+	case OpSetBitrate:
+		dev.SetBitrate(uint32(req.Value))
+	case OpOutputPower:
+		resp.Value = int64(dev.OutputPower())
+	case OpSetOutputPower:
+		dev.SetOutputPower(int(req.Value))
+	case OpSettings:
+		settings := dev.Settings()
+		resp.Settings = &settings
+	case OpApplySettings:
+		if req.Settings == nil {
+			resp.Error = "missing settings"
+			break
+		}
+		dev.ApplySettings(*req.Settings)
+	case OpState:
+		resp.Text = dev.State()
+	case OpName:
+		resp.Text = dev.Name()
+	case OpSend:
+		dev.Send(req.Data)
+	default:
+		resp.Error = "unknown operation " + req.Op
+	}
+	err := dev.Error()
+	if err != nil {
+		resp.Error = err.Error()
+		dev.SetError(nil)
+	}
+	cmd.reply <- resp
+}
@@ -3,6 +3,9 @@ package rfm95
 import (
 	"fmt"
 	"log"
+	"math"
+	"strconv"
+	"time"
 )
 
 const (
@@ -13,7 +16,13 @@ const (
 // ReadConfiguration reads the current register configuration from the radio,
 // using either burst-mode or individual SPI reads.
 func (r *Radio) ReadConfiguration(useBurst bool) []byte {
-	if r.Error() != nil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readConfiguration(useBurst)
+}
+
+func (r *Radio) readConfiguration(useBurst bool) []byte {
+	if r.error() != nil {
 		return nil
 	}
 	n := len(resetConfiguration)
@@ -32,6 +41,12 @@ func (r *Radio) ReadConfiguration(useBurst bool) []byte {
 // WriteConfiguration writes the given register configuration to the radio,
 // using either burst-mode or individual SPI writes.
 func (r *Radio) WriteConfiguration(config []byte, useBurst bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeConfiguration(config, useBurst)
+}
+
+func (r *Radio) writeConfiguration(config []byte, useBurst bool) {
 	n := len(resetConfiguration)
 	if len(config) != n {
 		log.Panicf("WriteConfiguration: config length = %d, expected %d", len(config), n)
@@ -50,9 +65,19 @@ func (r *Radio) WriteConfiguration(config []byte, useBurst bool) {
 // InitRF initializes the radio to communicate with
 // a Medtronic insulin pump at the given frequency.
 func (r *Radio) InitRF(frequency uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.initRF(frequency)
+}
+
+func (r *Radio) initRF(frequency uint32) {
+	if !r.validFrequency(frequency) {
+		return
+	}
 	// Must be in Sleep mode first before changing to FSK/OOK mode.
 	r.setMode(SleepMode)
-	rf := DefaultConfiguration()
+	c := r.chip()
+	rf := c.configuration(DefaultConfiguration())
 	rf[RegOpMode] = FskOokMode | ModulationTypeOOK | SleepMode
 	// Interrupt on DIO2 when Sync word is seen.
 	rf[RegDioMapping1] = 3 << Dio2MappingShift
@@ -74,73 +99,271 @@ func (r *Radio) InitRF(frequency uint32) {
 	rf[RegPacketConfig2] = PacketMode | 0
 	// Must use PA_BOOST pin on Adafruit RFM95 bonnet. This sets Pout = 3 dBm.
 	rf[RegPaConfig] = PaBoost | 1<<OutputPowerShift
-	rf[RegPaRamp] = ModulationShapingNarrow | PaRamp100μs
-	r.WriteConfiguration(rf, true)
-	r.SetFrequency(frequency)
-	r.SetBitrate(bitrate)
-	r.SetChannelBW(channelBW)
+	rf[RegPaRamp] = rf[RegPaRamp]&^PaRampMask | PaRamp100μs
+	c.setModulationShaping(rf, ModulationShapingNarrow)
+	r.writeConfiguration(rf, true)
+	r.setFrequency(frequency)
+	r.setBitrate(bitrate)
+	r.setChannelBW(channelBW)
 	// RegPaDac is not in the DefaultConfiguration range, so set it individually.
-	r.hw.WriteRegister(RegPaDac, PaDacDefault)
+	r.hw.WriteRegister(c.regPaDac, PaDacDefault)
+	r.setTCXO()
 }
 
 // Frequency returns the radio's current frequency, in Hertz.
 func (r *Radio) Frequency() uint32 {
-	return registersToFrequency(r.hw.ReadBurst(RegFrfMsb, 3))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frequency()
+}
+
+func (r *Radio) frequency() uint32 {
+	frf := r.hw.ReadBurst(RegFrfMsb, 3)
+	if r.error() != nil {
+		return 0
+	}
+	return registersToFrequency(frf, r.xo())
 }
 
-func registersToFrequency(frf []byte) uint32 {
+// The xo parameter of the conversion functions is the
+// oscillator frequency, corrected for crystal error (see SetPPM).
+func registersToFrequency(frf []byte, xo uint32) uint32 {
 	f := uint32(frf[0])<<16 + uint32(frf[1])<<8 + uint32(frf[2])
-	return uint32(uint64(f) * FXOSC >> 19)
+	return uint32(uint64(f) * uint64(xo) >> 19)
 }
 
 // SetFrequency sets the radio to the given frequency, in Hertz.
+// The frequency must be supported by the radio's variant
+// and allowed by the active channel plan, if any.
 func (r *Radio) SetFrequency(freq uint32) {
-	r.hw.WriteBurst(RegFrfMsb, frequencyToRegisters(freq))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setFrequency(freq)
 }
 
-func frequencyToRegisters(freq uint32) []byte {
-	f := (uint64(freq)<<19 + FXOSC/2) / FXOSC
+func (r *Radio) setFrequency(freq uint32) {
+	if !r.validFrequency(freq) {
+		return
+	}
+	r.setLowFrequencyMode(lowFrequency(freq))
+	r.hw.WriteBurst(RegFrfMsb, frequencyToRegisters(freq, r.xo()))
+}
+
+func frequencyToRegisters(freq uint32, xo uint32) []byte {
+	f := (uint64(freq)<<19 + uint64(xo)/2) / uint64(xo)
 	return []byte{byte(f >> 16), byte(f >> 8), byte(f)}
 }
 
+// Hertz converts a frequency given in MHz or Hz to Hertz,
+// rounding to the nearest Hz.
+// Values less than 1000 are taken to be in MHz.
+func Hertz(f float64) (uint32, error) {
+	if f < 1000 {
+		f *= 1000000
+	}
+	if !(f > 0 && f+0.5 < math.MaxUint32+1) {
+		return 0, fmt.Errorf("%g: invalid frequency", f)
+	}
+	return uint32(f + 0.5), nil
+}
+
+// ParseFrequency parses a frequency given in MHz or Hz (see Hertz).
+func ParseFrequency(s string) (uint32, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid frequency", s)
+	}
+	return Hertz(f)
+}
+
+// Output power limits when using the PA_BOOST pin, in dBm.
+const (
+	minOutputPower = 2
+	maxOutputPower = 20
+)
+
+// OutputPower returns the radio's output power, in dBm.
+func (r *Radio) OutputPower() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	paConfig := r.hw.ReadRegister(RegPaConfig)
+	paDac := r.hw.ReadRegister(r.chip().regPaDac)
+	return registersToPower(paConfig, paDac)
+}
+
+// SetOutputPower sets the radio's output power on the PA_BOOST pin, in dBm.
+// The power must be allowed by the active channel plan, if any.
+func (r *Radio) SetOutputPower(dBm int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if dBm < minOutputPower || dBm > maxOutputPower {
+		r.setError(fmt.Errorf("output power %d dBm is outside the range %d to %d dBm", dBm, minOutputPower, maxOutputPower))
+		return
+	}
+	if r.plan != nil && dBm > r.plan.MaxPower {
+		r.setError(fmt.Errorf("output power %d dBm exceeds the %s limit of %d dBm", dBm, r.plan.Name, r.plan.MaxPower))
+		return
+	}
+	paConfig, paDac := powerToRegisters(dBm)
+	r.hw.WriteRegister(RegPaConfig, paConfig)
+	r.hw.WriteRegister(r.chip().regPaDac, paDac)
+}
+
+// See data sheet section 5.4.3.
+// Pout = 2 + OutputPower, or 5 + OutputPower with the +20 dBm option.
+func powerToRegisters(dBm int) (byte, byte) {
+	if dBm > 17 {
+		return PaBoost | byte(dBm-5)<<OutputPowerShift, PaDacPlus20dBm
+	}
+	return PaBoost | byte(dBm-2)<<OutputPowerShift, PaDacDefault
+}
+
+func registersToPower(paConfig byte, paDac byte) int {
+	p := int(paConfig&OutputPowerMask) >> OutputPowerShift
+	if paDac&PaDacMask == PaDacPlus20dBm {
+		return 5 + p
+	}
+	return 2 + p
+}
+
+// LoRaBandwidth returns the radio's LoRa signal bandwidth, in Hertz.
+// It is meaningful only in LoRa mode.
+func (r *Radio) LoRaBandwidth() uint32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.chip().registerToLoRaBandwidth(r.hw.ReadRegister(RegLoRaModemConfig1))
+}
+
+// SetLoRaBandwidth sets the radio's LoRa signal bandwidth to the given value, in Hertz.
+// It is meaningful only in LoRa mode.
+func (r *Radio) SetLoRaBandwidth(bw uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.chip()
+	v := r.hw.ReadRegister(RegLoRaModemConfig1)
+	r.hw.WriteRegister(RegLoRaModemConfig1, v&^c.loraBwFieldMask|c.loraBandwidthToRegister(bw))
+}
+
 // ReadRSSI returns the radio's RSSI, in dBm.
 func (r *Radio) ReadRSSI() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readRSSI()
+}
+
+func (r *Radio) readRSSI() int {
 	rssi := r.hw.ReadRegister(RegRssiValue)
 	return -int(rssi) / 2
 }
 
+// ReadFEI returns the frequency error measured by the radio, in Hz.
+// It is only meaningful with FSK modulation.
+func (r *Radio) ReadFEI() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readFEI()
+}
+
+func (r *Radio) readFEI() int {
+	v := r.hw.ReadBurst(RegFeiMsb, 2)
+	if r.error() != nil {
+		return 0
+	}
+	return registersToFEI(v, r.xo())
+}
+
+func registersToFEI(v []byte, xo uint32) int {
+	fei := int16(uint16(v[0])<<8 | uint16(v[1]))
+	return int(math.Round(float64(fei) * fstep(xo)))
+}
+
+// FEI returns the frequency error measured during the most recent Receive, in Hz.
+func (r *Radio) FEI() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fei
+}
+
 // Bitrate returns the radio's bit rate, in bps.
 func (r *Radio) Bitrate() uint32 {
-	return registersToBitrate(r.hw.ReadBurst(RegBitrateMsb, 2))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readBitrate()
+}
+
+func (r *Radio) readBitrate() uint32 {
+	b := r.hw.ReadBurst(RegBitrateMsb, 2)
+	frac := byte(0)
+	if r.fractionalBitrate() {
+		frac = r.hw.ReadRegister(r.chip().regBitRateFrac)
+	}
+	if r.error() != nil {
+		return 0
+	}
+	return registersToBitrate(append(b, frac), r.xo())
+}
+
+// fractionalBitrate reports whether the BitRateFrac register is in effect.
+// It is only used with FSK modulation.
+func (r *Radio) fractionalBitrate() bool {
+	return r.hw.ReadRegister(RegOpMode)&ModulationTypeMask == ModulationTypeFSK
 }
 
 // See data sheet section 4.2.1.
-func registersToBitrate(br []byte) uint32 {
-	d := uint32(br[0])<<8 + uint32(br[1])
-	return (FXOSC + d/2) / d
+// The br slice contains the RegBitrateMsb, RegBitrateLsb,
+// and RegBitRateFrac values.
+func registersToBitrate(br []byte, xo uint32) uint32 {
+	d := uint64(br[0])<<12 + uint64(br[1])<<4 + uint64(br[2]&0xF)
+	return uint32((16*uint64(xo) + d/2) / d)
 }
 
 // SetBitrate sets the radio's bit rate to the given rate, in bps.
+// With FSK modulation, the fractional part of the divider is used
+// to set the rate more precisely.
 func (r *Radio) SetBitrate(br uint32) {
-	r.hw.WriteBurst(RegBitrateMsb, bitrateToRegisters(br))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setBitrate(br)
+}
+
+func (r *Radio) setBitrate(br uint32) {
+	b := bitrateToRegisters(br, r.xo(), r.fractionalBitrate())
+	r.hw.WriteBurst(RegBitrateMsb, b[:2])
+	r.hw.WriteRegister(r.chip().regBitRateFrac, b[2])
 }
 
-func bitrateToRegisters(br uint32) []byte {
-	b := (FXOSC + br/2) / br
-	return []byte{byte(b >> 8), byte(b)}
+func bitrateToRegisters(br uint32, xo uint32, fractional bool) []byte {
+	if !fractional {
+		b := (xo + br/2) / br
+		return []byte{byte(b >> 8), byte(b), 0}
+	}
+	b := (16*uint64(xo) + uint64(br)/2) / uint64(br)
+	return []byte{byte(b >> 12), byte(b >> 4), byte(b & 0xF)}
 }
 
 // ReadModulationType returns the radio's modulation type.
 func (r *Radio) ReadModulationType() byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.hw.ReadRegister(RegOpMode) & ModulationTypeMask
 }
 
 // ChannelBW returns the radio's channel bandwidth, in Hertz.
 func (r *Radio) ChannelBW() uint32 {
-	return registerToChannelBW(r.hw.ReadRegister(RegRxBw))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.channelBW()
 }
 
-func registerToChannelBW(bw byte) uint32 {
+func (r *Radio) channelBW() uint32 {
+	bw := r.hw.ReadRegister(RegRxBw)
+	if r.error() != nil {
+		return 0
+	}
+	return registerToChannelBW(bw, r.xo())
+}
+
+func registerToChannelBW(bw byte, xo uint32) uint32 {
 	mant := 0
 	switch bw & RxBwMantMask {
 	case RxBwMant16:
@@ -153,18 +376,24 @@ func registerToChannelBW(bw byte) uint32 {
 		log.Panicf("unknown RX bandwidth mantissa (%X)", bw&RxBwMantMask)
 	}
 	e := bw & RxBwExpMask
-	return uint32(FXOSC) / (uint32(mant) << (e + 2))
+	return xo / (uint32(mant) << (e + 2))
 }
 
 // SetChannelBW sets the radio's channel bandwidth to the given value, in Hertz.
 func (r *Radio) SetChannelBW(bw uint32) {
-	r.hw.WriteRegister(RegRxBw, channelBWToRegister(bw))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setChannelBW(bw)
+}
+
+func (r *Radio) setChannelBW(bw uint32) {
+	r.hw.WriteRegister(RegRxBw, channelBWToRegister(bw, r.xo()))
 }
 
 // Channel BW = FXOSC / (RxBwMant * 2^(RxBwExp + 2)).
-func channelBWToRegister(bw uint32) byte {
-	bb := uint32(2604) // lowest possible channel bandwidth
+func channelBWToRegister(bw uint32, xo uint32) byte {
 	rr := byte(RxBwMant24 | 7<<RxBwExpShift)
+	bb := registerToChannelBW(rr, xo) // lowest possible channel bandwidth
 	if bw < bb {
 		return rr
 	}
@@ -173,7 +402,7 @@ func channelBWToRegister(bw uint32) byte {
 		e := byte(7 - i)
 		for j := 0; j < 3; j++ {
 			m := byte((6 - j) * 4)
-			b := uint32(FXOSC) / (uint32(m) << (e + 2))
+			b := xo / (uint32(m) << (e + 2))
 			r := byte(2-j)<<RxBwMantShift | e<<RxBwExpShift
 			if b >= bw {
 				if b-bw < bw-bb {
@@ -193,28 +422,29 @@ func (r *Radio) mode() byte {
 }
 
 func (r *Radio) setMode(mode uint8) {
-	r.SetError(nil)
+	r.setError(nil)
 	cur := r.hw.ReadRegister(RegOpMode)
 	if cur&ModeMask == mode {
 		return
 	}
 	r.hw.WriteRegister(RegOpMode, cur&^ModeMask|mode)
-	if debug {
-		log.Printf("change from %s to %s", stateName(cur&ModeMask), stateName(mode))
-	}
-	for r.Error() == nil {
+	start := time.Now()
+	for r.error() == nil {
 		s := r.mode()
 		if s == mode {
 			break
 		}
-		if debug {
-			log.Printf("  %s", stateName(s))
-		}
+		r.logger.Debug("waiting for mode change", "state", stateName(s))
 	}
+	elapsed := time.Since(start)
+	r.recordModeChange(elapsed)
+	r.logger.Debug("mode change", "from", stateName(cur&ModeMask), "to", stateName(mode), "elapsed", elapsed)
 }
 
 // Sleep puts the radio into sleep mode.
 func (r *Radio) Sleep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.setMode(SleepMode)
 }
 
@@ -239,5 +469,11 @@ func stateName(mode uint8) string {
 
 // State returns the radio's current state as a string.
 func (r *Radio) State() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state()
+}
+
+func (r *Radio) state() string {
 	return stateName(r.mode())
 }
@@ -20,11 +20,11 @@ func TestFrequency(t *testing.T) {
 		{916600000, []byte{0xE5, 0x26, 0x66}, 916599975},
 	}
 	for _, c := range cases {
-		b := frequencyToRegisters(c.f)
+		b := frequencyToRegisters(c.f, FXOSC)
 		if !bytes.Equal(b, c.b) {
 			t.Errorf("frequencyToRegisters(%d) == % X, want % X", c.f, b, c.b)
 		}
-		f := registersToFrequency(c.b)
+		f := registersToFrequency(c.b, FXOSC)
 		if c.fApprox != 0 {
 			if f != c.fApprox {
 				t.Errorf("registersToFrequency(% X) == %d, want %d", c.b, f, c.fApprox)
@@ -40,26 +40,34 @@ func TestFrequency(t *testing.T) {
 // See data sheet Table 18 Bit Rate Examples
 func TestBitrate(t *testing.T) {
 	cases := []struct {
-		br       uint32
-		b        []byte
-		brApprox uint32 // 0 => equal to br
+		br         uint32
+		fractional bool
+		b          []byte
+		brApprox   uint32 // 0 => equal to br
 	}{
-		{1200, []byte{0x68, 0x2B}, 0},
-		{2400, []byte{0x34, 0x15}, 0},
-		{25000, []byte{0x05, 0x00}, 0},
-		{50000, []byte{0x02, 0x80}, 0},
-		// some that can't be represented exactly:
-		{16384, []byte{0x07, 0xA1}, 16385},
-		{19200, []byte{0x06, 0x83}, 19196},
-		{38400, []byte{0x03, 0x41}, 38415},
-		{150000, []byte{0x00, 0xD5}, 150235},
+		{1200, false, []byte{0x68, 0x2B, 0x00}, 0},
+		{2400, false, []byte{0x34, 0x15, 0x00}, 0},
+		{25000, false, []byte{0x05, 0x00, 0x00}, 0},
+		{50000, false, []byte{0x02, 0x80, 0x00}, 0},
+		// some that can't be represented exactly without the fractional part:
+		{16384, false, []byte{0x07, 0xA1, 0x00}, 16385},
+		{19200, false, []byte{0x06, 0x83, 0x00}, 19196},
+		{38400, false, []byte{0x03, 0x41, 0x00}, 38415},
+		{150000, false, []byte{0x00, 0xD5, 0x00}, 150235},
+		// fractional divider (FSK only):
+		{1200, true, []byte{0x68, 0x2A, 0x0B}, 0},
+		{25000, true, []byte{0x05, 0x00, 0x00}, 0},
+		{16384, true, []byte{0x07, 0xA1, 0x02}, 0},
+		{19200, true, []byte{0x06, 0x82, 0x0B}, 0},
+		{38400, true, []byte{0x03, 0x41, 0x05}, 38401},
+		{150000, true, []byte{0x00, 0xD5, 0x05}, 150015},
 	}
 	for _, c := range cases {
-		b := bitrateToRegisters(c.br)
+		b := bitrateToRegisters(c.br, FXOSC, c.fractional)
 		if !bytes.Equal(b, c.b) {
-			t.Errorf("bitrateToRegisters(%d) == % X, want % X", c.br, b, c.b)
+			t.Errorf("bitrateToRegisters(%d, %v) == % X, want % X", c.br, c.fractional, b, c.b)
 		}
-		f := registersToBitrate(c.b)
+		f := registersToBitrate(c.b, FXOSC)
 		if c.brApprox != 0 {
 			if f != c.brApprox {
 				t.Errorf("registersToBitrate(% X) == %d, want %d", c.b, f, c.brApprox)
@@ -93,11 +101,11 @@ func TestChannelBW(t *testing.T) {
 		{300000, RxBwMant16 | 1<<RxBwExpShift, 250000},
 	}
 	for _, c := range cases {
-		r := channelBWToRegister(c.bw)
+		r := channelBWToRegister(c.bw, FXOSC)
 		if r != c.r {
 			t.Errorf("channelBWToRegister(%d) == %02X, want %02X", c.bw, r, c.r)
 		}
-		bw := registerToChannelBW(c.r)
+		bw := registerToChannelBW(c.r, FXOSC)
 		if c.bwApprox != 0 {
 			if bw != c.bwApprox {
 				t.Errorf("registerToChannelBW(%02X) == %d, want %d", c.r, bw, c.bwApprox)
@@ -109,3 +117,54 @@ func TestChannelBW(t *testing.T) {
 		}
 	}
 }
+
+func TestFEI(t *testing.T) {
+	cases := []struct {
+		r   []byte
+		fei int
+	}{
+		{[]byte{0x00, 0x00}, 0},
+		{[]byte{0x00, 0x01}, 61},
+		{[]byte{0xFF, 0xFF}, -61},
+		{[]byte{0x00, 0x10}, 977},
+		{[]byte{0xFF, 0xF0}, -977},
+		{[]byte{0x80, 0x00}, -2000000},
+	}
+	for _, c := range cases {
+		fei := registersToFEI(c.r, FXOSC)
+		if fei != c.fei {
+			t.Errorf("registersToFEI(% X) == %d, want %d", c.r, fei, c.fei)
+		}
+	}
+}
+
+func TestParseFrequency(t *testing.T) {
+	cases := []struct {
+		s    string
+		freq uint32
+		ok   bool
+	}{
+		{"916.6", 916600000, true},
+		{"868.3", 868300000, true},
+		{"434.0001", 434000100, true},
+		{"916600000", 916600000, true},
+		{"916600000.4", 916600000, true},
+		{"916600000.6", 916600001, true},
+		{"0", 0, false},
+		{"-915", 0, false},
+		{"5e9", 0, false},
+		{"NaN", 0, false},
+		{"915MHz", 0, false},
+		{"", 0, false},
+	}
+	for _, c := range cases {
+		f, err := ParseFrequency(c.s)
+		if (err == nil) != c.ok {
+			t.Errorf("ParseFrequency(%q) error: %v, want ok == %v", c.s, err, c.ok)
+			continue
+		}
+		if f != c.freq {
+			t.Errorf("ParseFrequency(%q) == %d, want %d", c.s, f, c.freq)
+		}
+	}
+}
@@ -132,6 +132,49 @@ const (
 	RegPll         = 0x70 // Control of the PLL bandwidth
 )
 
+// LoRa mode registers (data sheet section 6.4).
+const (
+	RegLoRaFifoAddrPtr         = 0x0D // FIFO SPI pointer
+	RegLoRaFifoTxBaseAddr      = 0x0E // Start Tx data
+	RegLoRaFifoRxBaseAddr      = 0x0F // Start Rx data
+	RegLoRaFifoRxCurrentAddr   = 0x10 // Start address of last packet received
+	RegLoRaIrqFlagsMask        = 0x11 // Optional IRQ flag mask
+	RegLoRaIrqFlags            = 0x12 // IRQ flags
+	RegLoRaRxNbBytes           = 0x13 // Number of received bytes
+	RegLoRaRxHeaderCntValueMsb = 0x14 // Number of valid headers received, MSB
+	RegLoRaRxHeaderCntValueLsb = 0x15 // Number of valid headers received, LSB
+	RegLoRaRxPacketCntValueMsb = 0x16 // Number of valid packets received, MSB
+	RegLoRaRxPacketCntValueLsb = 0x17 // Number of valid packets received, LSB
+	RegLoRaModemStat           = 0x18 // Live LoRa modem status
+	RegLoRaPktSnrValue         = 0x19 // Estimation of last packet SNR
+	RegLoRaPktRssiValue        = 0x1A // RSSI of last packet
+	RegLoRaRssiValue           = 0x1B // Current RSSI
+	RegLoRaHopChannel          = 0x1C // FHSS start channel
+	RegLoRaModemConfig1        = 0x1D // Modem PHY config 1
+	RegLoRaModemConfig2        = 0x1E // Modem PHY config 2
+	RegLoRaSymbTimeoutLsb      = 0x1F // Receiver timeout value
+	RegLoRaPreambleMsb         = 0x20 // Size of preamble, MSB
+	RegLoRaPreambleLsb         = 0x21 // Size of preamble, LSB
+	RegLoRaPayloadLength       = 0x22 // LoRa payload length
+	RegLoRaMaxPayloadLength    = 0x23 // LoRa maximum payload length
+	RegLoRaHopPeriod           = 0x24 // FHSS hop period
+	RegLoRaFifoRxByteAddr      = 0x25 // Address of last byte written in FIFO
+	RegLoRaModemConfig3        = 0x26 // Modem PHY config 3
+	RegLoRaFeiMsb              = 0x28 // Estimated frequency error, MSB
+	RegLoRaFeiMid              = 0x29 // Estimated frequency error, middle bits
+	RegLoRaFeiLsb              = 0x2A // Estimated frequency error, LSB
+	RegLoRaRssiWideband        = 0x2C // Wideband RSSI measurement
+	RegLoRaIfFreq2             = 0x2F // IF frequency optimization
+	RegLoRaIfFreq1             = 0x30 // IF frequency optimization
+	RegLoRaDetectOptimize      = 0x31 // LoRa detection optimize for SF6
+	RegLoRaInvertIQ            = 0x33 // Invert LoRa I and Q signals
+	RegLoRaHighBwOptimize1     = 0x36 // Sensitivity optimization for 500 kHz bandwidth
+	RegLoRaDetectionThreshold  = 0x37 // LoRa detection threshold for SF6
+	RegLoRaSyncWord            = 0x39 // LoRa sync word
+	RegLoRaHighBwOptimize2     = 0x3A // Sensitivity optimization for 500 kHz bandwidth
+	RegLoRaInvertIQ2           = 0x3B // Optimize for inverted IQ
+)
+
 // Skip RegFifo to avoid burst mode access.
 const ConfigurationStart = RegOpMode
 
@@ -209,7 +252,7 @@ var resetConfiguration = []byte{
 
 // ResetConfiguration returns a copy of the register values after reset.
 func ResetConfiguration() []byte {
-	return resetConfiguration[:]
+	return append([]byte(nil), resetConfiguration...)
 }
 
 // defaultConfiguration contains the default (FSK) values,
@@ -285,7 +328,7 @@ var defaultConfiguration = []byte{
 
 // DefaultConfiguration returns a copy of the default (recommended) values.
 func DefaultConfiguration() []byte {
-	return defaultConfiguration[:]
+	return append([]byte(nil), defaultConfiguration...)
 }
 
 // RegOpMode
@@ -297,6 +340,8 @@ const (
 	ModulationTypeFSK  = 0 << 5
 	ModulationTypeOOK  = 1 << 5
 
+	LowFrequencyModeOn = 1 << 3
+
 	ModeMask        = 7
 	SleepMode       = 0
 	StandbyMode     = 1
@@ -309,11 +354,15 @@ const (
 // RegPaConfig
 const (
 	PaBoost          = 1 << 7
+	MaxPowerShift    = 4
+	MaxPowerMask     = 7 << 4
 	OutputPowerShift = 0
+	OutputPowerMask  = 0xF << 0
 )
 
 // RegPaRamp
 const (
+	ModulationShapingShift  = 5
 	ModulationShapingNone   = 0 << 5
 	ModulationShapingNarrow = 1 << 5
 	ModulationShapingWide   = 2 << 5
@@ -333,16 +382,21 @@ const (
 	PaRamp15μs              = 0xD
 	PaRamp12μs              = 0xE
 	PaRamp10μs              = 0xF
+	PaRampMask              = 0xF
 )
 
 // RegLna
 const (
+	LnaGainShift    = 5
+	LnaGainMask     = 7 << 5
 	LnaGainMax      = 1 << 5
 	LnaGainMax_6dB  = 2 << 5
 	LnaGainMax_12dB = 3 << 5
 	LnaGainMax_24dB = 4 << 5
 	LnaGainMax_36dB = 5 << 5
 	LnaGainMax_48dB = 6 << 5
+	LnaBoostHfMask  = 3 << 0
+	LnaBoostHfOn    = 3 << 0
 )
 
 // RegRxConfig
@@ -364,10 +418,38 @@ const (
 	RxBwExpMask   = 7 << 0
 )
 
+// RegOokPeak
+const (
+	BitSyncOn              = 1 << 5
+	OokThreshTypeShift     = 3
+	OokThreshTypeMask      = 3 << 3
+	OokPeakThreshStepShift = 0
+	OokPeakThreshStepMask  = 7 << 0
+)
+
+// RegOokAvg
+const (
+	OokPeakThreshDecShift = 5
+	OokPeakThreshDecMask  = 7 << 5
+)
+
+// RegPreambleDetect
+const (
+	PreambleDetectorOn        = 1 << 7
+	PreambleDetectorSizeShift = 5
+	PreambleDetectorSizeMask  = 3 << 5
+	PreambleDetectorTolShift  = 0
+	PreambleDetectorTolMask   = 0x1F << 0
+)
+
 // RegSyncConfig
 const (
-	SyncOn        = 1 << 4
-	SyncSizeShift = 0
+	PreamblePolarityAA   = 0 << 5
+	PreamblePolarity55   = 1 << 5
+	PreamblePolarityMask = 1 << 5
+	SyncOn               = 1 << 4
+	SyncSizeShift        = 0
+	SyncSizeMask         = 7 << 0
 )
 
 // RegPacketConfig1
@@ -375,15 +457,22 @@ const (
 	FixedLength           = 0 << 7
 	VariableLength        = 1 << 7
 	DcFreeShift           = 5
+	DcFreeMask            = 3 << 5
 	CrcOn                 = 1 << 4
 	CrcOff                = 0 << 4
 	CrcAutoClearOff       = 1 << 3
 	AddressFilteringShift = 1
+	AddressFilteringMask  = 3 << 1
+
+	AddressFilteringNone            = 0 << 1
+	AddressFilteringNode            = 1 << 1
+	AddressFilteringNodeOrBroadcast = 2 << 1
 )
 
 // RegPacketConfig2
 const (
 	PacketMode           = 1 << 6
+	ContinuousMode       = 0 << 6
 	PayloadLengthMSBMask = 7
 )
 
@@ -435,6 +524,10 @@ const (
 	Dio1MappingShift = 4
 	Dio2MappingShift = 2
 	Dio3MappingShift = 0
+	Dio0MappingMask  = 3 << 6
+	Dio1MappingMask  = 3 << 4
+	Dio2MappingMask  = 3 << 2
+	Dio3MappingMask  = 3 << 0
 )
 
 // RegDioMapping2
@@ -445,8 +538,19 @@ const (
 	MapRssi           = 0 << 0
 )
 
+// RegPllHop
+const (
+	FastHopOn = 1 << 7
+)
+
+// RegTcxo
+const (
+	TcxoInputOn = 1 << 4
+)
+
 // RegPaDac
 const (
 	PaDacDefault   = 0x04
 	PaDacPlus20dBm = 0x07
+	PaDacMask      = 0x07
 )
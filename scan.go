@@ -0,0 +1,80 @@
+package rfm95
+
+import (
+	"time"
+)
+
+// rssiSampleInterval is the time between RSSI readings while dwelling
+// on a frequency. It is longer than the default RSSI smoothing period.
+const rssiSampleInterval = time.Millisecond
+
+// RSSISample summarizes the RSSI readings at a single frequency.
+type RSSISample struct {
+	Frequency uint32 // Hz
+	Max       int    // dBm
+	Mean      int    // dBm
+}
+
+// SweepFrequencies returns the frequencies from start to stop (inclusive)
+// in increments of step.
+func SweepFrequencies(start, stop, step uint32) []uint32 {
+	if step == 0 || stop < start {
+		return nil
+	}
+	n := (stop-start)/step + 1
+	freqs := make([]uint32, n)
+	for i := range freqs {
+		freqs[i] = start + uint32(i)*step
+	}
+	return freqs
+}
+
+// SampleRSSI tunes the receiver to the given frequency,
+// reads the RSSI repeatedly for the dwell time,
+// and returns the maximum and mean values.
+func (r *Radio) SampleRSSI(freq uint32, dwell time.Duration) RSSISample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := RSSISample{Frequency: freq}
+	if r.error() != nil {
+		return s
+	}
+	r.setMode(StandbyMode)
+	r.setFrequency(freq)
+	r.setMode(ReceiverMode)
+	sum, n := 0, 0
+	deadline := time.Now().Add(dwell)
+	for r.error() == nil {
+		time.Sleep(rssiSampleInterval)
+		rssi := r.readRSSI()
+		if n == 0 || rssi > s.Max {
+			s.Max = rssi
+		}
+		sum += rssi
+		n++
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+	if n != 0 {
+		s.Mean = sum / n
+	}
+	return s
+}
+
+// Sweep samples the RSSI at each of the given frequencies
+// and leaves the radio in standby mode.
+func (r *Radio) Sweep(freqs []uint32, dwell time.Duration) []RSSISample {
+	samples := make([]RSSISample, 0, len(freqs))
+	for _, f := range freqs {
+		s := r.SampleRSSI(f, dwell)
+		if r.Error() != nil {
+			break
+		}
+		samples = append(samples, s)
+	}
+	r.mu.Lock()
+	r.setMode(StandbyMode)
+	r.mu.Unlock()
+	return samples
+}
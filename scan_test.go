@@ -0,0 +1,31 @@
+package rfm95
+
+import (
+	"testing"
+)
+
+func TestSweepFrequencies(t *testing.T) {
+	cases := []struct {
+		start, stop, step uint32
+		freqs             []uint32
+	}{
+		{916000000, 917000000, 250000, []uint32{916000000, 916250000, 916500000, 916750000, 917000000}},
+		{916000000, 916900000, 250000, []uint32{916000000, 916250000, 916500000, 916750000}},
+		{868100000, 868100000, 100000, []uint32{868100000}},
+		{917000000, 916000000, 100000, nil},
+		{916000000, 917000000, 0, nil},
+	}
+	for _, c := range cases {
+		freqs := SweepFrequencies(c.start, c.stop, c.step)
+		if len(freqs) != len(c.freqs) {
+			t.Errorf("SweepFrequencies(%d, %d, %d) == %v, want %v", c.start, c.stop, c.step, freqs, c.freqs)
+			continue
+		}
+		for i := range freqs {
+			if freqs[i] != c.freqs[i] {
+				t.Errorf("SweepFrequencies(%d, %d, %d) == %v, want %v", c.start, c.stop, c.step, freqs, c.freqs)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package rfm95
+
+import (
+	"fmt"
+	"math"
+)
+
+// Settings describes the FSK/OOK configuration of the radio in typed form.
+// DecodeSettings and Settings.Encode are exact inverses: encoding the
+// settings decoded from a configuration reproduces the configuration,
+// and decoding encoded settings reproduces the settings, provided the
+// settings were themselves decoded from (or representable in) the registers.
+type Settings struct {
+	Modulation        byte    // ModulationTypeFSK or ModulationTypeOOK
+	ModulationShaping byte    // one of the ModulationShaping constants
+	Frequency         uint32  // Hz
+	Bitrate           float64 // bps (not rounded, so that it converts back exactly)
+	Deviation         uint32  // Hz
+	RxBW              uint32  // Hz
+	AfcBW             uint32  // Hz
+
+	PreambleLength uint16 // bytes
+	SyncOn         bool
+	SyncWord       []byte // 1 to 8 bytes
+
+	VariableLength bool   // variable (true) or fixed (false) length packet format
+	PacketMode     bool   // packet (true) or continuous (false) data mode
+	PayloadLength  uint16 // 0 to 2047 bytes
+	CRC            bool
+
+	PaBoost       bool // PA_BOOST (true) or RFO (false) pin
+	PaMaxPower    byte // 0 to 7
+	PaOutputPower byte // 0 to 15
+	PaRamp        byte // one of the PaRamp constants
+
+	LnaGain    byte // 1 (maximum gain) to 6 (minimum gain)
+	LnaBoostHf bool
+	AgcAutoOn  bool
+	AfcAutoOn  bool
+
+	BitSyncOn         bool
+	OokThreshType     byte // 0: fixed, 1: peak, 2: average
+	OokPeakThreshStep byte // 0 to 7
+	OokPeakThreshDec  byte // 0 to 7
+	OokFixedThreshold byte // dB
+
+	DioMapping        [6]byte // mapping of pins DIO0 through DIO5, 0 to 3 each
+	MapPreambleDetect bool
+}
+
+// fstep returns the frequency synthesizer step, in Hertz (data sheet section 4.2.7).
+func fstep(xo uint32) float64 {
+	return float64(xo) / (1 << 19)
+}
+
+func deviationToRegisters(fdev uint32, xo uint32) []byte {
+	d := uint32(math.Round(float64(fdev) / fstep(xo)))
+	return []byte{byte(d>>8) & 0x3F, byte(d)}
+}
+
+func registersToDeviation(fd []byte, xo uint32) uint32 {
+	d := uint32(fd[0]&0x3F)<<8 | uint32(fd[1])
+	return uint32(math.Round(float64(d) * fstep(xo)))
+}
+
+// ifSet returns v if b is true, and 0 otherwise.
+func ifSet(b bool, v byte) byte {
+	if b {
+		return v
+	}
+	return 0
+}
+
+// setField replaces the bits of config[addr] selected by mask.
+func setField(config []byte, addr byte, mask byte, v byte) {
+	config[addr] = config[addr]&^mask | v&mask
+}
+
+// DecodeSettings decodes the FSK/OOK settings in a configuration,
+// such as the result of ReadConfiguration.
+func DecodeSettings(config []byte) Settings {
+	return decodeSettings(sx1276, FXOSC, config)
+}
+
+func decodeSettings(c *chip, xo uint32, config []byte) Settings {
+	s := Settings{
+		Modulation:        config[RegOpMode] & ModulationTypeMask,
+		ModulationShaping: c.modulationShaping(config),
+		Frequency:         registersToFrequency(config[RegFrfMsb:RegFrfLsb+1], xo),
+		Deviation:         registersToDeviation(config[RegFdevMsb:RegFdevLsb+1], xo),
+		RxBW:              registerToChannelBW(config[RegRxBw], xo),
+		AfcBW:             registerToChannelBW(config[RegAfcBw], xo),
+
+		PreambleLength: uint16(config[RegPreambleMsb])<<8 | uint16(config[RegPreambleLsb]),
+		SyncOn:         config[RegSyncConfig]&SyncOn != 0,
+
+		VariableLength: config[RegPacketConfig1]&VariableLength != 0,
+		PacketMode:     config[RegPacketConfig2]&PacketMode != 0,
+		PayloadLength:  uint16(config[RegPacketConfig2]&PayloadLengthMSBMask)<<8 | uint16(config[RegPayloadLength]),
+		CRC:            config[RegPacketConfig1]&CrcOn != 0,
+
+		PaBoost:       config[RegPaConfig]&PaBoost != 0,
+		PaMaxPower:    (config[RegPaConfig] & MaxPowerMask) >> MaxPowerShift,
+		PaOutputPower: (config[RegPaConfig] & OutputPowerMask) >> OutputPowerShift,
+		PaRamp:        config[RegPaRamp] & PaRampMask,
+
+		LnaGain:    (config[RegLna] & LnaGainMask) >> LnaGainShift,
+		LnaBoostHf: config[RegLna]&LnaBoostHfMask == LnaBoostHfOn,
+		AgcAutoOn:  config[RegRxConfig]&AgcAutoOn != 0,
+		AfcAutoOn:  config[RegRxConfig]&AfcAutoOn != 0,
+
+		BitSyncOn:         config[RegOokPeak]&BitSyncOn != 0,
+		OokThreshType:     (config[RegOokPeak] & OokThreshTypeMask) >> OokThreshTypeShift,
+		OokPeakThreshStep: (config[RegOokPeak] & OokPeakThreshStepMask) >> OokPeakThreshStepShift,
+		OokPeakThreshDec:  (config[RegOokAvg] & OokPeakThreshDecMask) >> OokPeakThreshDecShift,
+		OokFixedThreshold: config[RegOokFix],
+
+		MapPreambleDetect: config[RegDioMapping2]&MapPreambleDetect != 0,
+	}
+	d := uint32(config[RegBitrateMsb])<<8 | uint32(config[RegBitrateLsb])
+	if d != 0 {
+		s.Bitrate = float64(xo) / float64(d)
+	}
+	n := int(config[RegSyncConfig]&SyncSizeMask)>>SyncSizeShift + 1
+	s.SyncWord = append([]byte(nil), config[RegSyncValue1:RegSyncValue1+n]...)
+	dio := uint16(config[RegDioMapping1])<<8 | uint16(config[RegDioMapping2])
+	for i := range s.DioMapping {
+		s.DioMapping[i] = byte(dio>>(14-2*uint(i))) & 3
+	}
+	return s
+}
+
+// Encode stores the settings in a configuration, such as the result of
+// ReadConfiguration or DefaultConfiguration. Bits not described by
+// Settings are left unchanged.
+func (s *Settings) Encode(config []byte) error {
+	return s.encode(sx1276, FXOSC, config)
+}
+
+func (s *Settings) validate(xo uint32) error {
+	switch {
+	case s.Modulation != ModulationTypeFSK && s.Modulation != ModulationTypeOOK:
+		return fmt.Errorf("invalid modulation type %02X", s.Modulation)
+	case s.ModulationShaping&^(3<<ModulationShapingShift) != 0:
+		return fmt.Errorf("invalid modulation shaping %02X", s.ModulationShaping)
+	case s.Bitrate < 1 || math.Round(float64(xo)/s.Bitrate) > 0xFFFF:
+		return fmt.Errorf("bit rate %g is out of range", s.Bitrate)
+	case s.Deviation > uint32(math.Round(0x3FFF*fstep(xo))):
+		return fmt.Errorf("frequency deviation %d is out of range", s.Deviation)
+	case len(s.SyncWord) < 1 || len(s.SyncWord) > 8:
+		return fmt.Errorf("sync word length %d is not between 1 and 8", len(s.SyncWord))
+	case s.PayloadLength > 0x7FF:
+		return fmt.Errorf("payload length %d is out of range", s.PayloadLength)
+	case s.PaMaxPower > 7 || s.PaOutputPower > 15 || s.PaRamp > PaRampMask:
+		return fmt.Errorf("invalid PA settings")
+	case s.LnaGain < 1 || s.LnaGain > 6:
+		return fmt.Errorf("LNA gain %d is out of range", s.LnaGain)
+	case s.OokThreshType > 2 || s.OokPeakThreshStep > 7 || s.OokPeakThreshDec > 7:
+		return fmt.Errorf("invalid OOK settings")
+	}
+	for i, m := range s.DioMapping {
+		if m > 3 {
+			return fmt.Errorf("invalid DIO%d mapping %d", i, m)
+		}
+	}
+	return nil
+}
+
+func (s *Settings) encode(c *chip, xo uint32, config []byte) error {
+	err := s.validate(xo)
+	if err != nil {
+		return err
+	}
+	setField(config, RegOpMode, ModulationTypeMask, s.Modulation)
+	c.setModulationShaping(config, s.ModulationShaping)
+	copy(config[RegFrfMsb:], frequencyToRegisters(s.Frequency, xo))
+	d := uint32(math.Round(float64(xo) / s.Bitrate))
+	config[RegBitrateMsb] = byte(d >> 8)
+	config[RegBitrateLsb] = byte(d)
+	fd := deviationToRegisters(s.Deviation, xo)
+	setField(config, RegFdevMsb, 0x3F, fd[0])
+	config[RegFdevLsb] = fd[1]
+	setField(config, RegRxBw, RxBwMantMask|RxBwExpMask, channelBWToRegister(s.RxBW, xo))
+	setField(config, RegAfcBw, RxBwMantMask|RxBwExpMask, channelBWToRegister(s.AfcBW, xo))
+
+	config[RegPreambleMsb] = byte(s.PreambleLength >> 8)
+	config[RegPreambleLsb] = byte(s.PreambleLength)
+	setField(config, RegSyncConfig, SyncOn, ifSet(s.SyncOn, SyncOn))
+	setField(config, RegSyncConfig, SyncSizeMask, byte(len(s.SyncWord)-1)<<SyncSizeShift)
+	copy(config[RegSyncValue1:], s.SyncWord)
+
+	setField(config, RegPacketConfig1, VariableLength, ifSet(s.VariableLength, VariableLength))
+	setField(config, RegPacketConfig1, CrcOn, ifSet(s.CRC, CrcOn))
+	setField(config, RegPacketConfig2, PacketMode, ifSet(s.PacketMode, PacketMode))
+	setField(config, RegPacketConfig2, PayloadLengthMSBMask, byte(s.PayloadLength>>8))
+	config[RegPayloadLength] = byte(s.PayloadLength)
+
+	setField(config, RegPaConfig, PaBoost, ifSet(s.PaBoost, PaBoost))
+	setField(config, RegPaConfig, MaxPowerMask, s.PaMaxPower<<MaxPowerShift)
+	setField(config, RegPaConfig, OutputPowerMask, s.PaOutputPower<<OutputPowerShift)
+	setField(config, RegPaRamp, PaRampMask, s.PaRamp)
+
+	setField(config, RegLna, LnaGainMask, s.LnaGain<<LnaGainShift)
+	setField(config, RegLna, LnaBoostHfMask, ifSet(s.LnaBoostHf, LnaBoostHfOn))
+	setField(config, RegRxConfig, AgcAutoOn, ifSet(s.AgcAutoOn, AgcAutoOn))
+	setField(config, RegRxConfig, AfcAutoOn, ifSet(s.AfcAutoOn, AfcAutoOn))
+
+	setField(config, RegOokPeak, BitSyncOn, ifSet(s.BitSyncOn, BitSyncOn))
+	setField(config, RegOokPeak, OokThreshTypeMask, s.OokThreshType<<OokThreshTypeShift)
+	setField(config, RegOokPeak, OokPeakThreshStepMask, s.OokPeakThreshStep<<OokPeakThreshStepShift)
+	setField(config, RegOokAvg, OokPeakThreshDecMask, s.OokPeakThreshDec<<OokPeakThreshDecShift)
+	config[RegOokFix] = s.OokFixedThreshold
+
+	dio := uint16(0)
+	for i, m := range s.DioMapping {
+		dio |= uint16(m) << (14 - 2*uint(i))
+	}
+	config[RegDioMapping1] = byte(dio >> 8)
+	setField(config, RegDioMapping2, 0xF0, byte(dio))
+	setField(config, RegDioMapping2, MapPreambleDetect, ifSet(s.MapPreambleDetect, MapPreambleDetect))
+	return nil
+}
+
+// Settings reads the radio's current FSK/OOK settings.
+func (r *Radio) Settings() Settings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	config := r.readConfiguration(true)
+	if r.error() != nil {
+		return Settings{}
+	}
+	s := decodeSettings(r.chip(), r.xo(), config)
+	if s.Modulation == ModulationTypeFSK {
+		// RegBitRateFrac is outside the configuration range.
+		frac := r.hw.ReadRegister(r.chip().regBitRateFrac) & 0xF
+		d := uint32(config[RegBitrateMsb])<<8 | uint32(config[RegBitrateLsb])
+		if d != 0 {
+			s.Bitrate = 16 * float64(r.xo()) / float64(16*d+uint32(frac))
+		}
+	}
+	return s
+}
+
+// ApplySettings writes the given FSK/OOK settings to the radio.
+// The radio is left in sleep mode.
+func (r *Radio) ApplySettings(s Settings) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return
+	}
+	if !r.validFrequency(s.Frequency) {
+		return
+	}
+	r.setMode(SleepMode)
+	config := r.readConfiguration(true)
+	if r.error() != nil {
+		return
+	}
+	err := s.encode(r.chip(), r.xo(), config)
+	if err != nil {
+		r.setError(err)
+		return
+	}
+	// Use the fractional bit rate divider with FSK modulation.
+	frac := byte(0)
+	if s.Modulation == ModulationTypeFSK {
+		d := uint32(math.Round(16 * float64(r.xo()) / s.Bitrate))
+		if d>>4 <= 0xFFFF {
+			config[RegBitrateMsb] = byte(d >> 12)
+			config[RegBitrateLsb] = byte(d >> 4)
+			frac = byte(d & 0xF)
+		}
+	}
+	r.writeConfiguration(config, true)
+	r.hw.WriteRegister(r.chip().regBitRateFrac, frac)
+	r.setLowFrequencyMode(lowFrequency(s.Frequency))
+}
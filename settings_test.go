@@ -0,0 +1,95 @@
+package rfm95
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomConfiguration returns a configuration with random values
+// in the registers described by Settings.
+func randomConfiguration(rng *rand.Rand) []byte {
+	config := DefaultConfiguration()
+	for _, addr := range []byte{
+		RegFrfMsb, RegFrfMid, RegFrfLsb, RegBitrateMsb, RegBitrateLsb,
+		RegFdevMsb, RegFdevLsb, RegPreambleMsb, RegPreambleLsb,
+		RegSyncValue1, RegSyncValue2, RegSyncValue3, RegSyncValue4,
+		RegSyncValue5, RegSyncValue6, RegSyncValue7, RegSyncValue8,
+		RegPayloadLength, RegPaConfig, RegOokFix, RegDioMapping1,
+	} {
+		config[addr] = byte(rng.Intn(256))
+	}
+	if config[RegBitrateMsb] == 0 && config[RegBitrateLsb] == 0 {
+		config[RegBitrateLsb] = 1
+	}
+	config[RegFdevMsb] &= 0x3F
+	config[RegOpMode] = byte(rng.Intn(2))<<5 | SleepMode
+	config[RegPaRamp] = byte(rng.Intn(4))<<ModulationShapingShift | byte(rng.Intn(16))
+	config[RegRxBw] = byte(rng.Intn(3))<<RxBwMantShift | byte(1+rng.Intn(7))
+	config[RegAfcBw] = byte(rng.Intn(3))<<RxBwMantShift | byte(1+rng.Intn(7))
+	config[RegSyncConfig] = byte(rng.Intn(256)) &^ (1 << 3)
+	config[RegPacketConfig1] = byte(rng.Intn(256))
+	config[RegPacketConfig2] = byte(rng.Intn(128))
+	config[RegLna] = byte(1+rng.Intn(6))<<LnaGainShift | byte(rng.Intn(2)*3)
+	config[RegRxConfig] = byte(rng.Intn(256)) &^ (3 << 5)
+	config[RegOokPeak] = byte(rng.Intn(2))<<5 | byte(rng.Intn(3))<<OokThreshTypeShift | byte(rng.Intn(8))
+	config[RegOokAvg] = byte(rng.Intn(256)) &^ (1 << 4)
+	config[RegDioMapping2] = byte(rng.Intn(16))<<4 | byte(rng.Intn(2))
+	return config
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		config := randomConfiguration(rng)
+		s := DecodeSettings(config)
+		c := append([]byte(nil), config...)
+		err := s.Encode(c)
+		if err != nil {
+			t.Fatalf("Encode(%+v): %v", s, err)
+		}
+		if !bytes.Equal(c, config) {
+			t.Fatalf("Encode(DecodeSettings(c)) != c\n% X\n% X", c, config)
+		}
+		base := ResetConfiguration()
+		err = s.Encode(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := DecodeSettings(base); !reflect.DeepEqual(d, s) {
+			t.Fatalf("DecodeSettings(Encode(s)) != s\n%+v\n%+v", d, s)
+		}
+	}
+}
+
+func TestSettingsValues(t *testing.T) {
+	config := DefaultConfiguration()
+	s := DecodeSettings(config)
+	if s.Modulation != ModulationTypeFSK || s.Frequency != 434000000 || s.Deviation != 5005 {
+		t.Errorf("default settings: modulation %02X, frequency %d, deviation %d", s.Modulation, s.Frequency, s.Deviation)
+	}
+	if uint32(s.Bitrate+0.5) != 4800 {
+		t.Errorf("default bit rate == %g, want approximately 4800", s.Bitrate)
+	}
+	if s.RxBW != 10416 || !bytes.Equal(s.SyncWord, []byte{1, 1, 1, 1}) || s.PreambleLength != 3 {
+		t.Errorf("default settings: RX bandwidth %d, sync word % X, preamble %d", s.RxBW, s.SyncWord, s.PreambleLength)
+	}
+	s.SyncWord = nil
+	if err := s.Encode(config); err == nil {
+		t.Errorf("Encode accepted empty sync word")
+	}
+}
+
+func TestSettingsSX1272(t *testing.T) {
+	config := sx1272.configuration(DefaultConfiguration())
+	s := decodeSettings(sx1272, FXOSC, config)
+	s.ModulationShaping = ModulationShapingWide
+	err := s.encode(sx1272, FXOSC, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config[RegOpMode] != 0x11 || config[RegPaRamp] != 0x19 {
+		t.Errorf("SX1272 shaping: RegOpMode %02X, RegPaRamp %02X", config[RegOpMode], config[RegPaRamp])
+	}
+}
@@ -0,0 +1,161 @@
+package rfm95
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Stats holds counters describing the radio's activity since it was opened
+// or since the last call to ResetStats.
+type Stats struct {
+	PacketsSent     uint64
+	PacketsReceived uint64
+	BytesSent       uint64
+	BytesReceived   uint64
+	GlitchTrims     uint64 // end-of-packet glitch bytes removed
+	FIFOOverruns    uint64
+	CRCErrors       uint64 // reported with RecordCRCError
+	DecodeErrors    uint64 // packets discarded by the software decoder
+	Timeouts        uint64
+
+	ModeChangeSeconds Histogram // time to complete each mode change
+	RSSI              Histogram // RSSI of received packets, in dBm
+}
+
+// Histogram counts observations in buckets.
+// Counts[i] is the number of observations v with Bounds[i-1] < v <= Bounds[i];
+// the final element of Counts counts the observations above the last bound.
+type Histogram struct {
+	Bounds []float64
+	Counts []uint64
+	Sum    float64
+	Count  uint64
+}
+
+// Histogram bucket upper bounds.
+var (
+	modeChangeBounds = []float64{0.0001, 0.0002, 0.0005, 0.001, 0.002, 0.005, 0.01}
+	rssiBounds       = []float64{-120, -110, -100, -90, -80, -70, -60, -50, -40}
+)
+
+func newStats() Stats {
+	return Stats{
+		ModeChangeSeconds: newHistogram(modeChangeBounds),
+		RSSI:              newHistogram(rssiBounds),
+	}
+}
+
+func newHistogram(bounds []float64) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *Histogram) observe(v float64) {
+	if len(h.Counts) != len(h.Bounds)+1 {
+		*h = newHistogram(h.Bounds)
+	}
+	i := 0
+	for i < len(h.Bounds) && v > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Sum += v
+	h.Count++
+}
+
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+// Stats returns a snapshot of the radio's counters.
+func (r *Radio) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	s.ModeChangeSeconds = s.ModeChangeSeconds.clone()
+	s.RSSI = s.RSSI.clone()
+	return s
+}
+
+// ResetStats sets the radio's counters to zero.
+func (r *Radio) ResetStats() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = newStats()
+}
+
+// RecordCRCError counts a packet rejected by a checksum at a higher layer,
+// since packets are received without using the radio's own CRC.
+func (r *Radio) RecordCRCError() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.CRCErrors++
+}
+
+func (r *Radio) recordModeChange(d time.Duration) {
+	r.stats.ModeChangeSeconds.observe(d.Seconds())
+}
+
+// PublishStats publishes the radio's counters as an expvar variable
+// with the given name.  Like expvar.Publish, it panics if the name is already in use.
+func (r *Radio) PublishStats(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return r.Stats() }))
+}
+
+// MetricsHandler returns an HTTP handler that serves the radio's counters
+// in the Prometheus text exposition format.
+func (r *Radio) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = r.Stats().WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format,
+// with metric names prefixed by "rfm95_".
+func (s Stats) WritePrometheus(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	counters := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"packets_sent_total", "Packets transmitted.", s.PacketsSent},
+		{"packets_received_total", "Packets received.", s.PacketsReceived},
+		{"bytes_sent_total", "Payload bytes transmitted.", s.BytesSent},
+		{"bytes_received_total", "Payload bytes received.", s.BytesReceived},
+		{"glitch_trims_total", "End-of-packet glitch bytes removed.", s.GlitchTrims},
+		{"fifo_overruns_total", "FIFO overruns.", s.FIFOOverruns},
+		{"crc_errors_total", "Packets rejected by a checksum.", s.CRCErrors},
+		{"decode_errors_total", "Packets discarded by the decoder.", s.DecodeErrors},
+		{"timeouts_total", "Receive timeouts.", s.Timeouts},
+	}
+	for _, c := range counters {
+		fmt.Fprintf(b, "# HELP rfm95_%s %s\n", c.name, c.help)
+		fmt.Fprintf(b, "# TYPE rfm95_%s counter\n", c.name)
+		fmt.Fprintf(b, "rfm95_%s %d\n", c.name, c.value)
+	}
+	s.ModeChangeSeconds.writePrometheus(b, "mode_change_seconds", "Time to complete a mode change.")
+	s.RSSI.writePrometheus(b, "rssi_dbm", "RSSI of received packets.")
+	return b.Flush()
+}
+
+func (h Histogram) writePrometheus(w io.Writer, name string, help string) {
+	fmt.Fprintf(w, "# HELP rfm95_%s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE rfm95_%s histogram\n", name)
+	n := uint64(0)
+	for i, b := range h.Bounds {
+		if i < len(h.Counts) {
+			n += h.Counts[i]
+		}
+		fmt.Fprintf(w, "rfm95_%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(b, 'g', -1, 64), n)
+	}
+	fmt.Fprintf(w, "rfm95_%s_bucket{le=\"+Inf\"} %d\n", name, h.Count)
+	fmt.Fprintf(w, "rfm95_%s_sum %s\n", name, strconv.FormatFloat(h.Sum, 'g', -1, 64))
+	fmt.Fprintf(w, "rfm95_%s_count %d\n", name, h.Count)
+}
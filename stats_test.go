@@ -0,0 +1,58 @@
+package rfm95
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{-100, -80, -60})
+	for _, v := range []float64{-110, -100, -90, -70, -65, -20} {
+		h.observe(v)
+	}
+	want := []uint64{2, 1, 2, 1}
+	if !reflect.DeepEqual(h.Counts, want) {
+		t.Errorf("Counts == %v, want %v", h.Counts, want)
+	}
+	if h.Sum != -455 || h.Count != 6 {
+		t.Errorf("Sum, Count == %g, %d, want -455, 6", h.Sum, h.Count)
+	}
+	// The zero value has a single bucket.
+	var z Histogram
+	z.observe(1)
+	if !reflect.DeepEqual(z.Counts, []uint64{1}) {
+		t.Errorf("zero Histogram Counts == %v, want [1]", z.Counts)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	s := newStats()
+	s.PacketsSent = 3
+	s.BytesSent = 42
+	s.RSSI.observe(-95)
+	s.RSSI.observe(-45)
+	var buf bytes.Buffer
+	err := s.WritePrometheus(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE rfm95_packets_sent_total counter\nrfm95_packets_sent_total 3\n",
+		"rfm95_bytes_sent_total 42\n",
+		"rfm95_timeouts_total 0\n",
+		"# TYPE rfm95_rssi_dbm histogram\n",
+		`rfm95_rssi_dbm_bucket{le="-100"} 0` + "\n",
+		`rfm95_rssi_dbm_bucket{le="-90"} 1` + "\n",
+		`rfm95_rssi_dbm_bucket{le="-40"} 2` + "\n",
+		`rfm95_rssi_dbm_bucket{le="+Inf"} 2` + "\n",
+		"rfm95_rssi_dbm_sum -140\nrfm95_rssi_dbm_count 2\n",
+		`rfm95_mode_change_seconds_bucket{le="0.0001"} 0` + "\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("WritePrometheus output does not contain %q", line)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package rfm95
+
+// https://www.semtech.com/products/wireless-rf/lora-connect/sx1272
+
+// SX1272 registers whose addresses differ from the SX1276.
+// Registers 0x01 through 0x42 are at the same addresses in both chips.
+const (
+	SX1272RegAgcRef      = 0x43 // Adjustment of the AGC thresholds
+	SX1272RegAgcThresh1  = 0x44
+	SX1272RegAgcThresh2  = 0x45
+	SX1272RegAgcThresh3  = 0x46
+	SX1272RegPllHop      = 0x4B // Control the fast frequency hopping mode
+	SX1272RegTcxo        = 0x58 // TCXO or XTAL input setting
+	SX1272RegPaDac       = 0x5A // Higher power settings of the PA
+	SX1272RegPll         = 0x5C // Control of the PLL bandwidth
+	SX1272RegPllLowPn    = 0x5E // Control of the Low Phase Noise PLL bandwidth
+	SX1272RegFormerTemp  = 0x6C // Stored temperature during the former IQ Calibration
+	SX1272RegBitRateFrac = 0x70 // Fractional part in the Bit Rate division ratio
+)
+
+// SX1272 RegPaRamp
+const (
+	SX1272LowPnTxPllOff = 1 << 4
+)
@@ -0,0 +1,41 @@
+package rfm95
+
+// TCXO reports whether the radio is clocked by an external TCXO
+// rather than its crystal oscillator.
+func (r *Radio) TCXO() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tcxo
+}
+
+// SetTCXO selects an external TCXO (true) or the crystal oscillator (false)
+// as the radio's clock source. The setting is preserved across Reset and InitRF.
+// The default is determined at build time by the tcxo tag.
+func (r *Radio) SetTCXO(on bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tcxo = on
+	r.setTCXO()
+}
+
+// setTCXO writes the clock source setting to the radio.
+// RegTcxo can only be changed in sleep mode.
+func (r *Radio) setTCXO() {
+	if r.error() != nil {
+		return
+	}
+	reg := r.chip().regTcxo
+	v := r.hw.ReadRegister(reg)
+	w := v &^ TcxoInputOn
+	if r.tcxo {
+		w |= TcxoInputOn
+	}
+	if w == v {
+		return
+	}
+	mode := r.mode()
+	r.setMode(SleepMode)
+	r.hw.WriteRegister(reg, w)
+	r.logger.Debug("clock input changed", "tcxo", r.tcxo)
+	r.setMode(mode)
+}
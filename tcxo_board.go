@@ -0,0 +1,8 @@
+//go:build tcxo
+// +build tcxo
+
+package rfm95
+
+// Module with an external TCXO connected to the XTA pin.
+
+const boardTCXO = true
@@ -0,0 +1,8 @@
+//go:build !tcxo
+// +build !tcxo
+
+package rfm95
+
+// Default clock source is the crystal oscillator.
+
+const boardTCXO = false
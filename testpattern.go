@@ -0,0 +1,123 @@
+package rfm95
+
+import (
+	"fmt"
+	"time"
+)
+
+// TestPattern selects the signal emitted by TransmitTestPattern.
+type TestPattern int
+
+// Test patterns.
+const (
+	// Unmodulated carrier at the configured frequency.
+	PatternCarrier TestPattern = iota
+	// OOK modulation with alternating 1 and 0 bits, at the configured bitrate.
+	PatternSquareWave
+	// PN9 pseudo-random sequence, with the configured modulation.
+	PatternPN9
+)
+
+func (p TestPattern) String() string {
+	switch p {
+	case PatternCarrier:
+		return "carrier"
+	case PatternSquareWave:
+		return "square wave"
+	case PatternPN9:
+		return "PN9"
+	default:
+		return fmt.Sprintf("Unknown TestPattern (%d)", int(p))
+	}
+}
+
+// TransmitTestPattern transmits the given test pattern continuously
+// at the current frequency and output power until stop is closed.
+// The previous register configuration is restored afterwards,
+// with the radio in sleep mode.
+// The radio remains locked until then, so calls from other goroutines,
+// including Error, block until the transmission stops.
+func (r *Radio) TransmitTestPattern(p TestPattern, stop <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.error() != nil {
+		return
+	}
+	if p < PatternCarrier || p > PatternPN9 {
+		r.setError(fmt.Errorf("invalid test pattern %d", int(p)))
+		return
+	}
+	saved := r.readConfiguration(true)
+	if r.error() != nil {
+		return
+	}
+	defer func() {
+		r.setMode(SleepMode)
+		saved[RegOpMode] = saved[RegOpMode]&^ModeMask | SleepMode
+		r.writeConfiguration(saved, true)
+	}()
+	// Modulation changes require sleep mode.
+	r.setMode(SleepMode)
+	r.logger.Debug("transmitting test pattern", "pattern", p.String())
+	if p == PatternCarrier {
+		r.transmitCarrier(stop)
+		return
+	}
+	if p == PatternSquareWave {
+		mode := r.hw.ReadRegister(RegOpMode)
+		r.hw.WriteRegister(RegOpMode, mode&^ModulationTypeMask|ModulationTypeOOK)
+	}
+	r.transmitPattern(p, stop)
+}
+
+// transmitCarrier uses FSK modulation with zero deviation,
+// so the data on DIO2 in continuous mode has no effect.
+func (r *Radio) transmitCarrier(stop <-chan struct{}) {
+	mode := r.hw.ReadRegister(RegOpMode)
+	r.hw.WriteRegister(RegOpMode, mode&^ModulationTypeMask|ModulationTypeFSK)
+	r.hw.WriteBurst(RegFdevMsb, []byte{0, 0})
+	cfg := r.hw.ReadRegister(RegPacketConfig2)
+	r.hw.WriteRegister(RegPacketConfig2, cfg&^PacketMode|ContinuousMode)
+	r.setMode(TransmitterMode)
+	<-stop
+}
+
+// transmitPattern keeps the FIFO filled with the pattern,
+// using the unlimited length packet format.
+func (r *Radio) transmitPattern(p TestPattern, stop <-chan struct{}) {
+	cfg := r.hw.ReadRegister(RegPacketConfig1)
+	r.hw.WriteRegister(RegPacketConfig1, cfg&^(VariableLength|DcFreeMask|CrcOn|AddressFilteringMask))
+	r.hw.WriteRegister(RegPayloadLength, 0)
+	r.hw.WriteRegister(RegPacketConfig2, PacketMode|0)
+	r.hw.WriteRegister(RegFifoThresh, TxStartCondition|fifoThreshold<<FifoThresholdShift)
+	r.clearFIFO()
+	g := newPN9()
+	next := func() byte {
+		if p == PatternSquareWave {
+			return 0x55
+		}
+		return g.next()
+	}
+	buf := make([]byte, fifoSize-fifoThreshold-1)
+	fill := func() {
+		for i := range buf {
+			buf[i] = next()
+		}
+		r.hw.WriteBurst(RegFifo, buf)
+	}
+	r.setMode(StandbyMode)
+	fill()
+	r.setMode(TransmitterMode)
+	for r.error() == nil {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if r.fifoThresholdExceeded() {
+			time.Sleep(byteDuration)
+			continue
+		}
+		fill()
+	}
+}
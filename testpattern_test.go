@@ -0,0 +1,105 @@
+package rfm95
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTestPatternString(t *testing.T) {
+	cases := []struct {
+		p TestPattern
+		s string
+	}{
+		{PatternCarrier, "carrier"},
+		{PatternSquareWave, "square wave"},
+		{PatternPN9, "PN9"},
+		{TestPattern(7), "Unknown TestPattern (7)"},
+	}
+	for _, c := range cases {
+		s := c.p.String()
+		if s != c.s {
+			t.Errorf("TestPattern(%d).String() == %q, want %q", int(c.p), s, c.s)
+		}
+	}
+}
+
+func TestTransmitTestPattern(t *testing.T) {
+	pn9 := newPN9()
+	pn9Start := []byte{pn9.next(), pn9.next(), pn9.next()}
+	cases := []struct {
+		p          TestPattern
+		modulation byte
+		packetCfg2 byte // RegPacketConfig2 while transmitting
+		fdev       []byte
+		fifo       []byte // start of the FIFO data
+	}{
+		{PatternCarrier, ModulationTypeFSK, 0x03, []byte{0, 0}, nil},
+		{PatternSquareWave, ModulationTypeOOK, PacketMode, []byte{0x01, 0x52}, []byte{0x55, 0x55, 0x55}},
+		{PatternPN9, ModulationTypeFSK, PacketMode, []byte{0x01, 0x52}, pn9Start},
+	}
+	for _, c := range cases {
+		r, hw := newFakeRadio()
+		hw.regs[RegOpMode] = ModulationTypeFSK | SleepMode
+		hw.regs[RegFdevMsb] = 0x01
+		hw.regs[RegFdevLsb] = 0x52
+		hw.regs[RegPacketConfig1] = VariableLength | CrcOn | AddressFilteringNode
+		hw.regs[RegPacketConfig2] = PacketMode | 0x03
+		hw.regs[RegPayloadLength] = 0x40
+		hw.regs[RegDioMapping1] = 3 << Dio2MappingShift
+		before := hw.regs
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			r.TransmitTestPattern(c.p, stop)
+			close(done)
+		}()
+		for {
+			hw.mu.Lock()
+			n := hw.txStarts
+			hw.mu.Unlock()
+			if n != 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(stop)
+		<-done
+		if r.Error() != nil {
+			t.Errorf("%v: %v", c.p, r.Error())
+			continue
+		}
+		regs := hw.txRegs
+		if m := regs[RegOpMode] & ModulationTypeMask; m != c.modulation {
+			t.Errorf("%v: modulation type %02X, want %02X", c.p, m, c.modulation)
+		}
+		if v := regs[RegPacketConfig2]; v != c.packetCfg2 {
+			t.Errorf("%v: RegPacketConfig2 == %02X while transmitting, want %02X", c.p, v, c.packetCfg2)
+		}
+		if fdev := regs[RegFdevMsb : RegFdevLsb+1]; !bytes.Equal(fdev, c.fdev) {
+			t.Errorf("%v: deviation registers == % X, want % X", c.p, fdev, c.fdev)
+		}
+		if c.fifo != nil {
+			if cfg := regs[RegPacketConfig1]; cfg != 0 || regs[RegPayloadLength] != 0 {
+				t.Errorf("%v: RegPacketConfig1, RegPayloadLength == %02X, %02X, want unlimited length format", c.p, cfg, regs[RegPayloadLength])
+			}
+			if !bytes.HasPrefix(hw.tx, c.fifo) {
+				t.Errorf("%v: FIFO data begins % X, want % X", c.p, hw.tx[:len(c.fifo)], c.fifo)
+			}
+		} else if len(hw.tx) != 0 {
+			t.Errorf("%v: wrote %d bytes to the FIFO, want none", c.p, len(hw.tx))
+		}
+		// The DIO mapping, including the receive interrupt, is left alone.
+		if regs[RegDioMapping1] != before[RegDioMapping1] {
+			t.Errorf("%v: RegDioMapping1 == %02X while transmitting, want %02X", c.p, regs[RegDioMapping1], before[RegDioMapping1])
+		}
+		// Stopping restores packet mode and the rest of the configuration.
+		if hw.regs != before {
+			for i := range before {
+				if hw.regs[i] != before[i] {
+					t.Errorf("%v: register %02X == %02X after stopping, want %02X", c.p, i, hw.regs[i], before[i])
+				}
+			}
+		}
+	}
+}
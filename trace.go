@@ -0,0 +1,483 @@
+package rfm95
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// A recording consists of a JSON-encoded header, describing the driver state
+// that is not held in the chip's registers, followed by one Transaction per line.
+
+type traceHeader struct {
+	Variant  Variant      `json:"variant"`
+	PPM      float64      `json:"ppm"`
+	TCXO     bool         `json:"tcxo"`
+	Encoding Encoding     `json:"encoding"`
+	Plan     *ChannelPlan `json:"plan,omitempty"`
+}
+
+// Transaction operations.
+const (
+	OpRead         = "read"
+	OpReadBurst    = "read_burst"
+	OpWrite        = "write"
+	OpWriteBurst   = "write_burst"
+	OpWait         = "wait"
+	OpStartCapture = "start_capture"
+	OpWaitEdge     = "wait_edge"
+	OpStopCapture  = "stop_capture"
+	OpReset        = "reset"
+	OpClose        = "close"
+)
+
+// Transaction is a recorded hardware access.
+type Transaction struct {
+	Op       string        `json:"op"`
+	Addr     byte          `json:"addr,omitempty"`
+	Data     []byte        `json:"data,omitempty"`    // value read or written, or pin level
+	Len      int           `json:"len,omitempty"`     // requested by ReadBurst
+	Timeout  time.Duration `json:"timeout,omitempty"` // requested by WaitInterrupt or WaitEdge
+	TimedOut bool          `json:"timed_out,omitempty"`
+	Err      string        `json:"err,omitempty"` // transport error state afterwards, or GPIO operation result
+	Start    time.Duration `json:"start"`         // since the recording began
+	Duration time.Duration `json:"duration"`
+}
+
+func (t Transaction) String() string {
+	switch t.Op {
+	case OpRead:
+		return fmt.Sprintf("%s %02X", t.Op, t.Addr)
+	case OpReadBurst:
+		return fmt.Sprintf("%s %02X (%d bytes)", t.Op, t.Addr, t.Len)
+	case OpWrite, OpWriteBurst:
+		return fmt.Sprintf("%s %02X % X", t.Op, t.Addr, t.Data)
+	case OpWaitEdge:
+		return fmt.Sprintf("%s from %v", t.Op, levelOf(t.Data))
+	default:
+		return t.Op
+	}
+}
+
+// Record starts writing every hardware transaction, with its timing, to w
+// until StopRecording is called. The recording can be replayed with Replay.
+// It returns an error if the radio is already being recorded.
+func (r *Radio) Record(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.hw.(*recorder); ok {
+		return errors.New("radio is already being recorded")
+	}
+	enc := json.NewEncoder(w)
+	err := enc.Encode(traceHeader{
+		Variant:  r.variant,
+		PPM:      r.ppm,
+		TCXO:     r.tcxo,
+		Encoding: r.encoding,
+		Plan:     r.plan,
+	})
+	if err != nil {
+		return err
+	}
+	r.hw = &recorder{Transport: r.hw, enc: enc, start: time.Now()}
+	return nil
+}
+
+// StopRecording stops recording hardware transactions.
+// It returns the first error encountered while writing the recording.
+func (r *Radio) StopRecording() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.hw.(*recorder)
+	if !ok {
+		return nil
+	}
+	r.hw = t.Transport
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// recorder is a Transport that records the transactions of another.
+type recorder struct {
+	Transport
+	start time.Time
+
+	mu  sync.Mutex // serializes writes to enc
+	enc *json.Encoder
+	end time.Duration // end of the latest transaction
+	err error
+}
+
+// gpioOp reports whether op is a GPIO operation,
+// whose error is its result rather than the transport error state.
+func gpioOp(op string) bool {
+	switch op {
+	case OpWait, OpStartCapture, OpWaitEdge, OpStopCapture, OpReset:
+		return true
+	default:
+		return false
+	}
+}
+
+func levelData(level bool) []byte {
+	if level {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func levelOf(data []byte) bool {
+	return len(data) == 1 && data[0] != 0
+}
+
+func (t *recorder) record(tx Transaction, start time.Time) {
+	tx.Start = start.Sub(t.start)
+	tx.Duration = time.Since(start)
+	if !gpioOp(tx.Op) {
+		if err := t.Transport.Error(); err != nil {
+			tx.Err = err.Error()
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if end := tx.Start + tx.Duration; end > t.end {
+		t.end = end
+	}
+	if t.err == nil {
+		t.err = t.enc.Encode(tx)
+	}
+}
+
+// now returns the end of the latest transaction, which the Replayer
+// can reproduce, rather than the current time.
+func (t *recorder) now() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.start.Add(t.end)
+}
+
+func (t *recorder) ReadRegister(addr byte) byte {
+	start := time.Now()
+	v := t.Transport.ReadRegister(addr)
+	t.record(Transaction{Op: OpRead, Addr: addr, Data: []byte{v}}, start)
+	return v
+}
+
+func (t *recorder) ReadBurst(addr byte, n int) []byte {
+	start := time.Now()
+	v := t.Transport.ReadBurst(addr, n)
+	t.record(Transaction{Op: OpReadBurst, Addr: addr, Data: v, Len: n}, start)
+	return v
+}
+
+func (t *recorder) WriteRegister(addr byte, value byte) {
+	start := time.Now()
+	t.Transport.WriteRegister(addr, value)
+	t.record(Transaction{Op: OpWrite, Addr: addr, Data: []byte{value}}, start)
+}
+
+func (t *recorder) WriteBurst(addr byte, data []byte) {
+	start := time.Now()
+	t.Transport.WriteBurst(addr, data)
+	t.record(Transaction{Op: OpWriteBurst, Addr: addr, Data: append([]byte(nil), data...)}, start)
+}
+
+func (t *recorder) WaitInterrupt(timeout time.Duration) error {
+	start := time.Now()
+	err := t.Transport.WaitInterrupt(timeout)
+	t.recordResult(Transaction{Op: OpWait, Timeout: timeout}, err, start)
+	return err
+}
+
+func (t *recorder) StartCapture() (bool, error) {
+	start := time.Now()
+	level, err := t.Transport.StartCapture()
+	t.recordResult(Transaction{Op: OpStartCapture, Data: levelData(level)}, err, start)
+	return level, err
+}
+
+func (t *recorder) WaitEdge(level bool, timeout time.Duration) error {
+	start := time.Now()
+	err := t.Transport.WaitEdge(level, timeout)
+	t.recordResult(Transaction{Op: OpWaitEdge, Data: levelData(level), Timeout: timeout}, err, start)
+	return err
+}
+
+func (t *recorder) StopCapture() error {
+	start := time.Now()
+	err := t.Transport.StopCapture()
+	t.recordResult(Transaction{Op: OpStopCapture}, err, start)
+	return err
+}
+
+func (t *recorder) Reset() error {
+	start := time.Now()
+	err := t.Transport.Reset()
+	t.recordResult(Transaction{Op: OpReset}, err, start)
+	return err
+}
+
+func (t *recorder) recordResult(tx Transaction, err error, start time.Time) {
+	if err != nil {
+		tx.Err = err.Error()
+		tx.TimedOut = isTimeout(err)
+	}
+	t.record(tx, start)
+}
+
+func (t *recorder) Close() {
+	start := time.Now()
+	t.Transport.Close()
+	t.record(Transaction{Op: OpClose}, start)
+}
+
+// DivergenceError indicates a hardware access during replay
+// that does not match the recording.
+type DivergenceError struct {
+	Index int          // position in the recording
+	Want  *Transaction // nil after the end of the recording
+	Got   *Transaction // nil if the replay ended early
+}
+
+func (e DivergenceError) Error() string {
+	switch {
+	case e.Want == nil:
+		return fmt.Sprintf("replay: %v after end of recording", e.Got)
+	case e.Got == nil:
+		return fmt.Sprintf("replay: ended before transaction %d (%v)", e.Index, e.Want)
+	default:
+		return fmt.Sprintf("replay: transaction %d is %v, recorded %v", e.Index, e.Got, e.Want)
+	}
+}
+
+// replayTimeoutError reproduces a recorded GPIO timeout.
+type replayTimeoutError struct {
+	msg string
+}
+
+func (e replayTimeoutError) Error() string {
+	return e.msg
+}
+
+func (replayTimeoutError) Timeout() bool {
+	return true
+}
+
+// Replayer is a Transport that plays back a recording.
+// Reads return the recorded values without delay, and the first access
+// that differs from the recording stops the replay with a DivergenceError.
+// Receive deadlines are measured against the recorded timing,
+// so the replay makes the same sequence of calls as the recording.
+type Replayer struct {
+	mu         sync.Mutex
+	txs        []Transaction
+	next       int
+	end        time.Duration // end of the latest replayed transaction
+	err        error
+	divergence error
+}
+
+// Replay returns a radio whose hardware transactions are played back
+// from the recording read from rd, and the Replayer that compares them.
+// Performing the same sequence of operations as the recorded session
+// reproduces its results; operations that depend on elapsed time
+// or on concurrent use of the radio may not replay exactly.
+func Replay(rd io.Reader) (*Radio, *Replayer, error) {
+	dec := json.NewDecoder(rd)
+	var h traceHeader
+	err := dec.Decode(&h)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h.Variant < 0 || int(h.Variant) >= len(variants) {
+		return nil, nil, fmt.Errorf("replay: unknown variant (%d)", int(h.Variant))
+	}
+	p := &Replayer{}
+	for {
+		var tx Transaction
+		err = dec.Decode(&tx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		p.txs = append(p.txs, tx)
+	}
+	r := newRadio(p)
+	r.variant = h.Variant
+	r.ppm = h.PPM
+	r.tcxo = h.TCXO
+	r.encoding = h.Encoding
+	r.plan = h.Plan
+	return r, p, nil
+}
+
+// Done returns the first divergence from the recording,
+// or a DivergenceError if the recording has not been replayed completely.
+func (p *Replayer) Done() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.divergence != nil {
+		return p.divergence
+	}
+	if p.next < len(p.txs) {
+		return DivergenceError{Index: p.next, Want: &p.txs[p.next]}
+	}
+	return nil
+}
+
+// replay matches the given access against the next recorded transaction.
+func (p *Replayer) replay(got Transaction) (Transaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.divergence != nil {
+		return Transaction{}, false
+	}
+	if p.next >= len(p.txs) {
+		p.divergence = DivergenceError{Index: p.next, Got: &got}
+		return Transaction{}, false
+	}
+	want := p.txs[p.next]
+	if !matches(want, got) {
+		p.divergence = DivergenceError{Index: p.next, Want: &want, Got: &got}
+		return Transaction{}, false
+	}
+	p.next++
+	if end := want.Start + want.Duration; end > p.end {
+		p.end = end
+	}
+	if gpioOp(want.Op) {
+		return want, true
+	}
+	switch {
+	case want.Err == "":
+		p.err = nil
+	case p.err == nil || p.err.Error() != want.Err:
+		p.err = errors.New(want.Err)
+	}
+	return want, true
+}
+
+func matches(want, got Transaction) bool {
+	if want.Op != got.Op || want.Addr != got.Addr {
+		return false
+	}
+	switch want.Op {
+	case OpReadBurst:
+		return want.Len == got.Len
+	case OpWrite, OpWriteBurst:
+		return string(want.Data) == string(got.Data)
+	case OpWaitEdge:
+		return levelOf(want.Data) == levelOf(got.Data)
+	default:
+		return true
+	}
+}
+
+// ReadRegister returns the recorded value.
+func (p *Replayer) ReadRegister(addr byte) byte {
+	tx, ok := p.replay(Transaction{Op: OpRead, Addr: addr})
+	if !ok || len(tx.Data) != 1 {
+		return 0
+	}
+	return tx.Data[0]
+}
+
+// ReadBurst returns the recorded values.
+func (p *Replayer) ReadBurst(addr byte, n int) []byte {
+	tx, ok := p.replay(Transaction{Op: OpReadBurst, Addr: addr, Len: n})
+	if !ok {
+		return nil
+	}
+	return tx.Data
+}
+
+// WriteRegister checks the write against the recording.
+func (p *Replayer) WriteRegister(addr byte, value byte) {
+	p.replay(Transaction{Op: OpWrite, Addr: addr, Data: []byte{value}})
+}
+
+// WriteBurst checks the write against the recording.
+func (p *Replayer) WriteBurst(addr byte, data []byte) {
+	p.replay(Transaction{Op: OpWriteBurst, Addr: addr, Data: data})
+}
+
+// WaitInterrupt returns the recorded result immediately.
+func (p *Replayer) WaitInterrupt(timeout time.Duration) error {
+	tx, ok := p.replay(Transaction{Op: OpWait, Timeout: timeout})
+	return p.result(tx, ok)
+}
+
+// StartCapture returns the recorded level and result.
+func (p *Replayer) StartCapture() (bool, error) {
+	tx, ok := p.replay(Transaction{Op: OpStartCapture})
+	return levelOf(tx.Data), p.result(tx, ok)
+}
+
+// WaitEdge returns the recorded result immediately.
+func (p *Replayer) WaitEdge(level bool, timeout time.Duration) error {
+	tx, ok := p.replay(Transaction{Op: OpWaitEdge, Data: levelData(level), Timeout: timeout})
+	return p.result(tx, ok)
+}
+
+// StopCapture returns the recorded result.
+func (p *Replayer) StopCapture() error {
+	tx, ok := p.replay(Transaction{Op: OpStopCapture})
+	return p.result(tx, ok)
+}
+
+// Reset returns the recorded result immediately.
+func (p *Replayer) Reset() error {
+	tx, ok := p.replay(Transaction{Op: OpReset})
+	return p.result(tx, ok)
+}
+
+// replayEpoch is the time at which replayed recordings begin.
+var replayEpoch = time.Unix(0, 0)
+
+func (p *Replayer) now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return replayEpoch.Add(p.end)
+}
+
+// result reproduces the recorded result of a GPIO operation.
+func (p *Replayer) result(tx Transaction, ok bool) error {
+	switch {
+	case !ok:
+		return p.Error()
+	case tx.TimedOut:
+		return replayTimeoutError{msg: tx.Err}
+	case tx.Err != "":
+		return errors.New(tx.Err)
+	}
+	return nil
+}
+
+// Close checks the close against the recording.
+func (p *Replayer) Close() {
+	p.replay(Transaction{Op: OpClose})
+}
+
+// Error returns the first divergence from the recording, if any,
+// and otherwise the recorded error state.
+func (p *Replayer) Error() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.divergence != nil {
+		return p.divergence
+	}
+	return p.err
+}
+
+// SetError sets the error state. It does not clear a divergence.
+func (p *Replayer) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
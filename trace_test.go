@@ -0,0 +1,92 @@
+package rfm95
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type session struct {
+	freq uint32
+	data []byte
+}
+
+func runSession(r *Radio, freq uint32) session {
+	r.Init(freq)
+	s := session{freq: r.Frequency()}
+	s.data, _ = r.Receive(50 * time.Millisecond)
+	r.Close()
+	return s
+}
+
+func TestReplay(t *testing.T) {
+	r := newRadio(&fakeTransport{})
+	r.variant = SX1276
+	r.ppm = 12.5
+	var buf bytes.Buffer
+	err := r.Record(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Record(&buf) == nil {
+		t.Errorf("repeated Record did not return an error")
+	}
+	if r.Hardware() != nil {
+		t.Errorf("Hardware() == %v for a fake transport, want nil", r.Hardware())
+	}
+	recorded := runSession(r, 915000000)
+	err = r.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.hw.(*fakeTransport); !ok {
+		t.Errorf("StopRecording left transport %T", r.hw)
+	}
+	trace := buf.Bytes()
+	if !bytes.Contains(trace, []byte(`"op":"reset"`)) {
+		t.Errorf("recording has no reset transaction")
+	}
+
+	rr, p, err := Replay(bytes.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.variant != SX1276 || rr.ppm != 12.5 {
+		t.Errorf("Replay restored variant %v and %g ppm, want %v and 12.5 ppm", rr.variant, rr.ppm, SX1276)
+	}
+	start := time.Now()
+	replayed := runSession(rr, 915000000)
+	if err := p.Done(); err != nil {
+		t.Errorf("replay diverged: %v", err)
+	}
+	// The reset and the receive timeout are replayed without waiting.
+	if d := time.Since(start); d >= 50*time.Millisecond {
+		t.Errorf("replay took %v, longer than the recorded receive timeout", d)
+	}
+	if replayed.freq != recorded.freq || !bytes.Equal(replayed.data, recorded.data) {
+		t.Errorf("replayed session %+v, recorded %+v", replayed, recorded)
+	}
+
+	rr, p, err = Replay(bytes.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSession(rr, 916000000)
+	var d DivergenceError
+	if err := p.Done(); !errors.As(err, &d) || d.Want == nil || d.Got == nil || d.Got.Op != OpWriteBurst {
+		t.Fatalf("replay with a different frequency returned %v", err)
+	}
+	if rr.Error() != p.Done() {
+		t.Errorf("radio error %v, want %v", rr.Error(), p.Done())
+	}
+
+	rr, p, err = Replay(bytes.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.Init(915000000)
+	if err := p.Done(); !errors.As(err, &d) || d.Got != nil {
+		t.Errorf("incomplete replay returned %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package rfm95
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ecc1/gpio"
+	"github.com/ecc1/radio"
+	"golang.org/x/sys/unix"
+)
+
+// Transport is the interface through which a Radio accesses the chip.
+// Open uses SPI and GPIO interrupt and reset pins; Record and Replay
+// interpose on it to capture and reproduce hardware transactions.
+type Transport interface {
+	ReadRegister(addr byte) byte
+	ReadBurst(addr byte, n int) []byte
+	WriteRegister(addr byte, value byte)
+	WriteBurst(addr byte, data []byte)
+
+	// WaitInterrupt waits with the given timeout for a receive interrupt.
+	// Errors, including timeouts, are returned rather than recorded
+	// in the transport's error state.
+	WaitInterrupt(timeout time.Duration) error
+
+	// StartCapture configures the interrupt pin to report both edges
+	// for continuous-mode edge capture, and returns its current level.
+	// StopCapture restores the receive interrupt configuration.
+	StartCapture() (bool, error)
+	// WaitEdge waits with the given timeout for the interrupt pin
+	// to change from the given level.
+	WaitEdge(level bool, timeout time.Duration) error
+	StopCapture() error
+
+	// Reset pulses the chip's reset pin and waits for the chip to start.
+	Reset() error
+
+	Error() error
+	SetError(err error)
+	Close()
+}
+
+// spiTransport accesses the chip through SPI and the GPIO interrupt pin.
+type spiTransport struct {
+	*radio.Hardware
+	// Use a separate copy of the interrupt pin, since the one in
+	// radio.Hardware records errors in the shared error state.
+	interrupt gpio.InterruptPin
+	// The value file of the interrupt pin during edge capture.
+	// It is polled directly, since gpio.Wait only waits for the active level.
+	edges *os.File
+}
+
+func (t *spiTransport) WaitInterrupt(timeout time.Duration) error {
+	return t.interrupt.Wait(timeout)
+}
+
+func (t *spiTransport) StartCapture() (bool, error) {
+	_, err := gpio.Interrupt(interruptPin, false, "both")
+	if err != nil {
+		return false, err
+	}
+	t.edges, err = os.Open(fmt.Sprintf("/sys/class/gpio/gpio%d/value", interruptPin))
+	if err != nil {
+		return false, err
+	}
+	return t.level()
+}
+
+// level reads the value file, which also acknowledges a pending edge.
+func (t *spiTransport) level() (bool, error) {
+	var buf [4]byte
+	_, err := t.edges.ReadAt(buf[:], 0)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return buf[0] == '1', nil
+}
+
+func (t *spiTransport) WaitEdge(level bool, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	fds := []unix.PollFd{{Fd: int32(t.edges.Fd()), Events: unix.POLLPRI}}
+	for {
+		v, err := t.level()
+		if err != nil || v != level {
+			return err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return edgeTimeoutError{timeout: timeout}
+		}
+		// Round up so that a sub-millisecond remainder does not spin.
+		ms := int((remaining + time.Millisecond - 1) / time.Millisecond)
+		_, err = unix.Poll(fds, ms)
+		if err != nil && err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+func (t *spiTransport) StopCapture() error {
+	var err error
+	if t.edges != nil {
+		err = t.edges.Close()
+		t.edges = nil
+	}
+	_, e := gpio.Interrupt(interruptPin, false, "rising")
+	if err == nil {
+		err = e
+	}
+	return err
+}
+
+// Reset resets the chip. See section 7.2.2 of data sheet.
+// NOTE: the RFM95 requires the reset pin to be in input mode
+// except while resetting the chip, unlike the RFM69 for example.
+func (t *spiTransport) Reset() error {
+	_, err := gpio.Output(resetPin, true, true)
+	time.Sleep(100 * time.Microsecond)
+	_, e := gpio.Input(resetPin, true)
+	if err == nil {
+		err = e
+	}
+	time.Sleep(5 * time.Millisecond)
+	return err
+}
+
+// edgeTimeoutError indicates that WaitEdge timed out.
+type edgeTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e edgeTimeoutError) Error() string {
+	return fmt.Sprintf("gpio%d edge timeout after %v", interruptPin, e.timeout)
+}
+
+func (edgeTimeoutError) Timeout() bool {
+	return true
+}
+
+// timeout is implemented by errors that indicate a timeout,
+// other than gpio.TimeoutError.
+type timeout interface {
+	Timeout() bool
+}
+
+// clock is implemented by transports that supply the time used for
+// receive deadlines, so that the sequence of interrupt waits in a
+// recorded session can be reproduced exactly when it is replayed.
+type clock interface {
+	now() time.Time
+}
+
+func (r *Radio) now() time.Time {
+	if c, ok := r.hw.(clock); ok {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func isTimeout(err error) bool {
+	if _, ok := err.(gpio.TimeoutError); ok {
+		return true
+	}
+	t, ok := err.(timeout)
+	return ok && t.Timeout()
+}
@@ -0,0 +1,168 @@
+package rfm95
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// fakeTransport is a register file whose mode changes take effect immediately.
+// Bytes in rx are returned by FIFO reads, and the receive interrupt occurs
+// while rx is non-empty; bytes written to the FIFO are appended to tx.
+// During edge capture, the interrupt pin takes each level in levels in turn.
+type fakeTransport struct {
+	mu        sync.Mutex
+	regs      [0x80]byte
+	rx        []byte
+	tx        []byte
+	rxStarts  int    // number of changes to receiver mode
+	txStarts  int    // number of changes to transmitter mode
+	txRegs    []byte // registers when transmitter mode was last entered
+	txConfig  []byte // RegPacketConfig1..3 at the last FIFO write
+	level     bool
+	levels    []bool
+	capturing bool
+	captures  int // number of completed captures
+	err       error
+}
+
+type fakeTimeout struct{}
+
+func (fakeTimeout) Error() string { return "interrupt timeout" }
+func (fakeTimeout) Timeout() bool { return true }
+
+func (t *fakeTransport) ReadRegister(addr byte) byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return 0
+	}
+	switch addr {
+	case RegFifo:
+		if len(t.rx) == 0 {
+			return 0
+		}
+		b := t.rx[0]
+		t.rx = t.rx[1:]
+		return b
+	case RegIrqFlags2:
+		if len(t.rx) == 0 {
+			return FifoEmpty
+		}
+		return 0
+	}
+	return t.regs[addr]
+}
+
+func (t *fakeTransport) ReadBurst(addr byte, n int) []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return nil
+	}
+	return append([]byte(nil), t.regs[addr:int(addr)+n]...)
+}
+
+func (t *fakeTransport) WriteRegister(addr byte, value byte) {
+	t.WriteBurst(addr, []byte{value})
+}
+
+func (t *fakeTransport) WriteBurst(addr byte, data []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = nil
+	switch addr {
+	case RegFifo:
+		t.tx = append(t.tx, data...)
+		t.txConfig = append([]byte(nil), t.regs[RegPacketConfig1:RegPacketConfig1+3]...)
+		return
+	case RegIrqFlags2:
+		return
+	case RegOpMode:
+		if data[0]&ModeMask == ReceiverMode && t.regs[RegOpMode]&ModeMask != ReceiverMode {
+			t.rxStarts++
+		}
+		if data[0]&ModeMask == TransmitterMode && t.regs[RegOpMode]&ModeMask != TransmitterMode {
+			t.txStarts++
+			t.txRegs = append([]byte(nil), t.regs[:]...)
+			t.txRegs[RegOpMode] = data[0]
+		}
+	}
+	copy(t.regs[addr:], data)
+}
+
+func (t *fakeTransport) WaitInterrupt(timeout time.Duration) error {
+	t.mu.Lock()
+	n := len(t.rx)
+	t.mu.Unlock()
+	if n != 0 {
+		return nil
+	}
+	time.Sleep(timeout)
+	return fakeTimeout{}
+}
+
+func (t *fakeTransport) StartCapture() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.capturing {
+		return false, errors.New("capture already started")
+	}
+	t.capturing = true
+	return t.level, nil
+}
+
+// WaitEdge times out immediately when there are no more levels.
+func (t *fakeTransport) WaitEdge(level bool, timeout time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.capturing {
+		return errors.New("capture not started")
+	}
+	for len(t.levels) != 0 {
+		t.level = t.levels[0]
+		t.levels = t.levels[1:]
+		if t.level != level {
+			return nil
+		}
+	}
+	return fakeTimeout{}
+}
+
+func (t *fakeTransport) StopCapture() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.capturing = false
+	t.captures++
+	return nil
+}
+
+// Reset restores the reset values of the registers.
+func (t *fakeTransport) Reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	copy(t.regs[:], resetConfiguration)
+	return nil
+}
+
+func (t *fakeTransport) Error() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+func (t *fakeTransport) SetError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+}
+
+func (*fakeTransport) Close() {}
+
+// newFakeRadio returns a radio using a fakeTransport.
+func newFakeRadio() (*Radio, *fakeTransport) {
+	t := &fakeTransport{}
+	r := newRadio(t)
+	r.variant = SX1276
+	return r, t
+}
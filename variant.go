@@ -0,0 +1,156 @@
+package rfm95
+
+import (
+	"fmt"
+	"log"
+)
+
+// Variant identifies a member of the SX127x / RFM9x family.
+// Only the chip family (SX1276 or SX1272) can be read from the chip;
+// the variant within a family is configured at build time
+// (see variant_default.go) or set with SetVariant.
+type Variant int
+
+// Supported variants.
+const (
+	SX1276 Variant = iota
+	SX1277
+	SX1278
+	SX1279
+	RFM95
+	RFM96
+	RFM97
+	RFM98
+	SX1272
+	SX1273
+	RFM92
+)
+
+// Band is a frequency range, in Hertz.
+type Band struct {
+	Min uint32
+	Max uint32
+}
+
+// Contains reports whether the given frequency is within the band.
+func (b Band) Contains(freq uint32) bool {
+	return b.Min <= freq && freq <= b.Max
+}
+
+// Frequencies below this use the low-frequency RF port (bands 2 and 3).
+const lowFrequencyLimit = 779000000
+
+// lowFrequency reports whether the given frequency requires LowFrequencyModeOn.
+func lowFrequency(freq uint32) bool {
+	return freq < lowFrequencyLimit
+}
+
+// Frequency bands from the SX1276/77/78/79 data sheet.
+var (
+	band1 = Band{862000000, 1020000000}
+	band2 = Band{410000000, 525000000}
+	band3 = Band{137000000, 175000000}
+
+	// The SX1279 has narrower bands.
+	sx1279Band1 = Band{779000000, 960000000}
+	sx1279Band2 = Band{410000000, 480000000}
+	sx1279Band3 = Band{137000000, 160000000}
+
+	// The SX1272/73 have a single band.
+	sx1272Band = Band{860000000, 1020000000}
+)
+
+type variantInfo struct {
+	name  string
+	chip  *chip
+	bands []Band
+	minSF int
+	maxSF int
+}
+
+// See data sheet section 1.2 and the HopeRF module data sheets.
+var variants = []variantInfo{
+	SX1276: {"SX1276", sx1276, []Band{band1, band2, band3}, 6, 12},
+	SX1277: {"SX1277", sx1276, []Band{band1, band2, band3}, 6, 9},
+	SX1278: {"SX1278", sx1276, []Band{band2, band3}, 6, 12},
+	SX1279: {"SX1279", sx1276, []Band{sx1279Band1, sx1279Band2, sx1279Band3}, 6, 12},
+	RFM95:  {"RFM95W", sx1276, []Band{band1}, 6, 12},
+	RFM96:  {"RFM96W", sx1276, []Band{band2}, 6, 12},
+	RFM97:  {"RFM97W", sx1276, []Band{band1}, 6, 9},
+	RFM98:  {"RFM98W", sx1276, []Band{band2}, 6, 12},
+	SX1272: {"SX1272", sx1272, []Band{sx1272Band}, 6, 12},
+	SX1273: {"SX1273", sx1272, []Band{sx1272Band}, 6, 9},
+	RFM92:  {"RFM92W", sx1272, []Band{sx1272Band}, 6, 12},
+}
+
+func (v Variant) info() variantInfo {
+	if v < 0 || int(v) >= len(variants) {
+		panic(fmt.Sprintf("unknown variant (%d)", v))
+	}
+	return variants[v]
+}
+
+func (v Variant) String() string {
+	if v < 0 || int(v) >= len(variants) {
+		return fmt.Sprintf("Unknown Variant (%d)", int(v))
+	}
+	return variants[v].name
+}
+
+// Bands returns the frequency bands supported by the variant.
+func (v Variant) Bands() []Band {
+	return v.info().bands
+}
+
+// SpreadingFactors returns the LoRa spreading factors supported by the variant.
+func (v Variant) SpreadingFactors() []int {
+	info := v.info()
+	sf := make([]int, 0, info.maxSF-info.minSF+1)
+	for i := info.minSF; i <= info.maxSF; i++ {
+		sf = append(sf, i)
+	}
+	return sf
+}
+
+// Validate checks that the given frequency, in Hertz, is supported by the variant.
+func (v Variant) Validate(freq uint32) error {
+	for _, b := range v.Bands() {
+		if b.Contains(freq) {
+			return nil
+		}
+	}
+	return FrequencyError{Frequency: freq, Band: v.String()}
+}
+
+// Variant returns the radio's chip or module variant.
+func (r *Radio) Variant() Variant {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.variant
+}
+
+// SetVariant sets the radio's chip or module variant,
+// which determines the allowed frequencies.
+// The variant must belong to the same chip family as the radio.
+func (r *Radio) SetVariant(v Variant) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v.info().chip != r.chip() {
+		log.Panicf("cannot change %v radio to %v variant", r.variant, v)
+	}
+	r.variant = v
+}
+
+func (r *Radio) setLowFrequencyMode(on bool) {
+	if !r.chip().hasLowFrequencyMode {
+		return
+	}
+	cur := r.hw.ReadRegister(RegOpMode)
+	v := cur &^ LowFrequencyModeOn
+	if on {
+		v |= LowFrequencyModeOn
+	}
+	if v != cur {
+		r.hw.WriteRegister(RegOpMode, v)
+	}
+}
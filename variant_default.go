@@ -0,0 +1,8 @@
+//go:build !rfm96
+// +build !rfm96
+
+package rfm95
+
+// Default module variant.
+
+const defaultVariant = RFM95
@@ -0,0 +1,8 @@
+//go:build rfm96
+// +build rfm96
+
+package rfm95
+
+// RFM96W (433 MHz) module on the same board layout.
+
+const defaultVariant = RFM96
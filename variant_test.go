@@ -0,0 +1,66 @@
+package rfm95
+
+import (
+	"testing"
+)
+
+func TestVariantValidate(t *testing.T) {
+	cases := []struct {
+		v  Variant
+		f  uint32
+		ok bool
+	}{
+		{RFM95, 916600000, true},
+		{RFM95, 868350000, true},
+		{RFM95, 433920000, false},
+		{RFM96, 433920000, true},
+		{RFM96, 916600000, false},
+		{RFM97, 915000000, true},
+		{RFM98, 470000000, true},
+		{SX1276, 150000000, true},
+		{SX1278, 868000000, false},
+		{SX1279, 800000000, true},
+		{SX1279, 500000000, false},
+	}
+	for _, c := range cases {
+		err := c.v.Validate(c.f)
+		if (err == nil) != c.ok {
+			t.Errorf("%v.Validate(%d) == %v, want ok == %v", c.v, c.f, err, c.ok)
+		}
+	}
+}
+
+func TestSpreadingFactors(t *testing.T) {
+	cases := []struct {
+		v        Variant
+		min, max int
+	}{
+		{SX1276, 6, 12},
+		{SX1277, 6, 9},
+		{RFM95, 6, 12},
+		{RFM97, 6, 9},
+	}
+	for _, c := range cases {
+		sf := c.v.SpreadingFactors()
+		if sf[0] != c.min || sf[len(sf)-1] != c.max || len(sf) != c.max-c.min+1 {
+			t.Errorf("%v.SpreadingFactors() == %v, want %d .. %d", c.v, sf, c.min, c.max)
+		}
+	}
+}
+
+func TestLowFrequency(t *testing.T) {
+	cases := []struct {
+		f  uint32
+		lf bool
+	}{
+		{433920000, true},
+		{169000000, true},
+		{868000000, false},
+		{916600000, false},
+	}
+	for _, c := range cases {
+		if lowFrequency(c.f) != c.lf {
+			t.Errorf("lowFrequency(%d) == %v, want %v", c.f, !c.lf, c.lf)
+		}
+	}
+}